@@ -16,9 +16,12 @@ import (
 
 	"github.com/trysourcetool/onprem-portal/internal/config"
 	"github.com/trysourcetool/onprem-portal/internal/encrypt"
+	"github.com/trysourcetool/onprem-portal/internal/jobs"
 	"github.com/trysourcetool/onprem-portal/internal/logger"
 	"github.com/trysourcetool/onprem-portal/internal/postgres"
+	"github.com/trysourcetool/onprem-portal/internal/registry"
 	"github.com/trysourcetool/onprem-portal/internal/server"
+	"github.com/trysourcetool/onprem-portal/internal/sign"
 )
 
 func init() {
@@ -36,10 +39,22 @@ func main() {
 	}
 
 	db := postgres.New(pqClient)
-	encryptor, err := encrypt.NewEncryptor()
+	keyProvider, err := encrypt.NewKeyProvider(db)
+	if err != nil {
+		logger.Logger.Fatal("failed to create key provider", zap.Error(err))
+	}
+	encryptor, err := encrypt.NewEncryptor(ctx, keyProvider)
 	if err != nil {
 		logger.Logger.Fatal("failed to create encryptor", zap.Error(err))
 	}
+	signer, err := sign.NewSigner()
+	if err != nil {
+		logger.Logger.Fatal("failed to create signer", zap.Error(err))
+	}
+	registryTokenIssuer, err := registry.NewTokenIssuer()
+	if err != nil {
+		logger.Logger.Fatal("failed to create registry token issuer", zap.Error(err))
+	}
 
 	// if config.Config.Env == config.EnvLocal {
 	// 	if err := internal.LoadFixtures(ctx, db); err != nil {
@@ -54,7 +69,7 @@ func main() {
 	}
 
 	handler := chi.NewRouter()
-	s := server.New(db, encryptor)
+	s := server.New(db, encryptor, signer, registryTokenIssuer)
 	s.Install(handler)
 
 	srv := &http.Server{
@@ -73,6 +88,9 @@ func main() {
 		}
 		return nil
 	})
+	eg.Go(func() error {
+		return jobs.NewRunner(db).Run(egCtx)
+	})
 	eg.Go(func() error {
 		<-egCtx.Done()
 		logger.Logger.Info("Shutting down server...")
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -10,6 +12,8 @@ import (
 	_ "github.com/lib/pq"
 
 	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/encrypt"
 	"github.com/trysourcetool/onprem-portal/internal/logger"
 	"github.com/trysourcetool/onprem-portal/internal/postgres"
 )
@@ -24,6 +28,11 @@ func main() {
 		fmt.Fprintf(flag.CommandLine.Output(), "usage: db [cmd] [args...]\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "Commands:\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  migrate [dir]: runs all migrations (default dir: migrations)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  grant-staff [email]: grants the user access to the admin API\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  reencrypt: re-seals license keys with the primary encryption key and associated data\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  create-data-key: generates a data key wrapped by ENCRYPTION_MASTER_KEY for the envelope key provider\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  import-data-key: wraps ENCRYPTION_KEY with ENCRYPTION_MASTER_KEY under ENCRYPTION_KEY_VERSION\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  rewrap-data-keys: re-wraps stored data keys bound to their version\n")
 	}
 
 	flag.Parse()
@@ -46,6 +55,23 @@ func run(ctx context.Context, cmd string) error {
 			dir = flag.Arg(1)
 		}
 		return migrate(dir)
+	case "grant-staff":
+		if flag.NArg() < 2 {
+			return errors.New("email is required")
+		}
+		return grantStaff(ctx, flag.Arg(1))
+	case "reencrypt":
+		return reencrypt(ctx)
+	case "create-data-key":
+		return storeDataKey(ctx, 0, nil)
+	case "import-data-key":
+		key, err := base64.StdEncoding.DecodeString(config.Config.Encryption.Key)
+		if err != nil {
+			return fmt.Errorf("invalid ENCRYPTION_KEY: %w", err)
+		}
+		return storeDataKey(ctx, config.Config.Encryption.KeyVersion, key)
+	case "rewrap-data-keys":
+		return rewrapDataKeys(ctx)
 	default:
 		return fmt.Errorf("unsupported arg: %q", cmd)
 	}
@@ -54,3 +80,146 @@ func run(ctx context.Context, cmd string) error {
 func migrate(dir string) error {
 	return postgres.Migrate(dir)
 }
+
+func grantStaff(ctx context.Context, email string) error {
+	sqlxDB, err := postgres.Open()
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+
+	db := postgres.New(sqlxDB)
+	return db.WithTx(ctx, func(tx database.Tx) error {
+		u, err := tx.User().GetByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		u.IsStaff = true
+		return tx.User().Update(ctx, u)
+	})
+}
+
+const reencryptBatchSize = 100
+
+// reencrypt re-seals every license that was sealed with a previous key or without
+// associated data, so that the previous key can be retired. It is safe to run while
+// the portal is serving traffic.
+func reencrypt(ctx context.Context) error {
+	sqlxDB, err := postgres.Open()
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+
+	db := postgres.New(sqlxDB)
+
+	keyProvider, err := encrypt.NewKeyProvider(db)
+	if err != nil {
+		return err
+	}
+	encryptor, err := encrypt.NewEncryptor(ctx, keyProvider)
+	if err != nil {
+		return err
+	}
+	primary := encryptor.PrimaryVersion()
+
+	var total int
+	for {
+		licenses, err := db.License().List(ctx,
+			database.LicenseNeedsReseal(primary),
+			database.LicenseLimit(reencryptBatchSize),
+		)
+		if err != nil {
+			return err
+		}
+		if len(licenses) == 0 {
+			break
+		}
+
+		for _, l := range licenses {
+			if err := db.WithTx(ctx, func(tx database.Tx) error {
+				// Reload under lock in case the key was rotated since it was listed.
+				l, err := tx.License().GetByIDForUpdate(ctx, l.ID)
+				if err != nil {
+					return err
+				}
+				if l.KeyVersion == primary && l.KeyAADBound {
+					return nil
+				}
+
+				var aad []byte
+				if l.KeyAADBound {
+					aad = l.KeyAAD()
+				}
+				plain, err := encryptor.DecryptWithAAD(l.KeyVersion, l.KeyCiphertext, l.KeyNonce, aad)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt license %s: %w", l.ID, err)
+				}
+
+				keyVersion, ciphertext, nonce, err := encryptor.EncryptWithAAD(plain, l.KeyAAD())
+				if err != nil {
+					return err
+				}
+				l.KeyCiphertext = ciphertext
+				l.KeyNonce = nonce
+				l.KeyVersion = keyVersion
+				l.KeyAADBound = true
+
+				return tx.License().Update(ctx, l)
+			}); err != nil {
+				return err
+			}
+		}
+		total += len(licenses)
+	}
+
+	log.Printf("re-sealed %d licenses with key version %d", total, primary)
+	return nil
+}
+
+// storeDataKey wraps key, or a freshly generated key if it is nil, with the master key and
+// stores it under version, or the next version if version is zero. Run reencrypt after
+// restarting the portal to re-seal existing licenses with the new primary key.
+func storeDataKey(ctx context.Context, version int, key []byte) error {
+	wrapper, err := encrypt.NewLocalKeyWrapper(config.Config.Encryption.MasterKey)
+	if err != nil {
+		return err
+	}
+
+	sqlxDB, err := postgres.Open()
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+
+	version, err = encrypt.StoreDataKey(ctx, postgres.New(sqlxDB), wrapper, version, key)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("stored data key version %d", version)
+	return nil
+}
+
+// rewrapDataKeys binds data keys stored before AAD was introduced to their version.
+// It is safe to run while the portal is serving traffic.
+func rewrapDataKeys(ctx context.Context) error {
+	wrapper, err := encrypt.NewLocalKeyWrapper(config.Config.Encryption.MasterKey)
+	if err != nil {
+		return err
+	}
+
+	sqlxDB, err := postgres.Open()
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+
+	n, err := encrypt.RewrapDataKeys(ctx, postgres.New(sqlxDB), wrapper)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("re-wrapped %d data keys", n)
+	return nil
+}
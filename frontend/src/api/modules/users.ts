@@ -1,9 +1,19 @@
 import * as api from '@/api/instance';
 
+export type LicenseStatus = 'active' | 'suspended' | 'expired' | 'revoked';
+
 export type License = {
   id: string;
+  organizationId: string;
   userId: string;
   key: string;
+  status: LicenseStatus;
+  isTrial: boolean;
+  expiresAt: string | null;
+  expiresInDays: number | null;
+  renewedAt: string | null;
+  createdAt: string;
+  updatedAt: string;
 };
 
 export type User = {
@@ -0,0 +1,176 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+)
+
+// Data fills the bundle templates.
+type Data struct {
+	OrganizationName string
+	LicenseKey       string
+	Image            string
+	Version          string
+	ReplicaCount     int
+	EncryptionKey    string
+	JWTKey           string
+	PostgresPassword string
+	RedisPassword    string
+}
+
+// GenerateSecrets fills the keys and passwords the installation needs with fresh
+// random values, so that no two bundles share them.
+func (d *Data) GenerateSecrets() error {
+	encryptionKey, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	jwtKey, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	postgresPassword, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	redisPassword, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+
+	d.EncryptionKey = base64.StdEncoding.EncodeToString(encryptionKey)
+	d.JWTKey = hex.EncodeToString(jwtKey)
+	d.PostgresPassword = hex.EncodeToString(postgresPassword)
+	d.RedisPassword = hex.EncodeToString(redisPassword)
+	return nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+var funcs = template.FuncMap{
+	"oneline": oneline,
+}
+
+// oneline replaces control characters such as line breaks with spaces, so that
+// user-provided text placed in a comment cannot start a new setting.
+func oneline(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.In(r, unicode.Zl, unicode.Zp) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+type file struct {
+	name     string
+	template string
+	mode     int64
+}
+
+// Files holding secrets are only readable by their owner.
+var files = []file{
+	{name: "docker-compose.yaml", template: "docker-compose.yaml.tmpl", mode: 0o644},
+	{name: ".env", template: "env.tmpl", mode: 0o600},
+	{name: "values.yaml", template: "values.yaml.tmpl", mode: 0o600},
+}
+
+// Write renders the bundle templates with d and writes them to w as an archive of
+// format, with every file under the root directory.
+func Write(w io.Writer, format, root string, d *Data, now time.Time) error {
+	rendered := make([][]byte, len(files))
+	for i, f := range files {
+		tmpl, err := template.New(f.template).Funcs(funcs).ParseFS(templates, path.Join("templates", f.template))
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, d); err != nil {
+			return fmt.Errorf("failed to render %s: %w", f.name, err)
+		}
+		rendered[i] = buf.Bytes()
+	}
+
+	switch format {
+	case FormatTarGz:
+		return writeTarGz(w, root, rendered, now)
+	case FormatZip:
+		return writeZip(w, root, rendered, now)
+	default:
+		return fmt.Errorf("unsupported bundle format %q", format)
+	}
+}
+
+func writeTarGz(w io.Writer, root string, rendered [][]byte, now time.Time) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for i, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(root, f.name),
+			Mode:    f.mode,
+			Size:    int64(len(rendered[i])),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(rendered[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func writeZip(w io.Writer, root string, rendered [][]byte, now time.Time) error {
+	zw := zip.NewWriter(w)
+
+	for i, f := range files {
+		h := &zip.FileHeader{
+			Name:     path.Join(root, f.name),
+			Method:   zip.Deflate,
+			Modified: now,
+		}
+		h.SetMode(fs.FileMode(f.mode))
+		fw, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(rendered[i]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
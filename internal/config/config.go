@@ -15,12 +15,65 @@ const (
 )
 
 type config struct {
-	BaseURL       string `env:"BASE_URL"`
-	Env           string `env:"ENV"`
-	EncryptionKey string `env:"ENCRYPTION_KEY"`
-	Jwt           struct {
+	BaseURL    string `env:"BASE_URL"`
+	Env        string `env:"ENV"`
+	Encryption struct {
+		// KeyProvider selects where data keys are loaded from: env, file or envelope.
+		KeyProvider string `env:"ENCRYPTION_KEY_PROVIDER" envDefault:"env"`
+		Key         string `env:"ENCRYPTION_KEY" envDefault:""`
+		KeyVersion  int    `env:"ENCRYPTION_KEY_VERSION" envDefault:"1"`
+		// PreviousKeys holds retired keys as comma separated version:key pairs.
+		// They are only used to decrypt data sealed before a rotation.
+		PreviousKeys string `env:"ENCRYPTION_PREVIOUS_KEYS" envDefault:""`
+		// KeyFile is read by the file provider. See encrypt.NewFileKeyProvider for the format.
+		KeyFile string `env:"ENCRYPTION_KEY_FILE" envDefault:""`
+		// MasterKey unwraps the data keys stored in Postgres for the envelope provider.
+		MasterKey string `env:"ENCRYPTION_MASTER_KEY" envDefault:""`
+	}
+	Jwt struct {
 		Key string `env:"JWT_KEY"`
 	}
+	URLSigning struct {
+		// Key signs expiring download URLs. It defaults to a key derived from JWT_KEY.
+		Key string `env:"URL_SIGNING_KEY" envDefault:""`
+	}
+	License struct {
+		SigningKey string `env:"LICENSE_SIGNING_KEY"`
+	}
+	Trial struct {
+		// Days is the length of the trial license issued at signup. Zero issues a
+		// perpetual license instead.
+		Days         int `env:"TRIAL_DAYS" envDefault:"14"`
+		ReminderDays int `env:"TRIAL_REMINDER_DAYS" envDefault:"3"`
+	}
+	Registry struct {
+		// SigningKey is the ECDSA P-256 private key, as PEM or base64 encoded PEM, that
+		// signs registry tokens. The token service is disabled when it is empty.
+		SigningKey string `env:"REGISTRY_SIGNING_KEY" envDefault:""`
+		// Issuer must match the registry's auth.token.issuer. It defaults to BASE_URL.
+		Issuer string `env:"REGISTRY_TOKEN_ISSUER" envDefault:""`
+		// Service must match the registry's auth.token.service when set.
+		Service string `env:"REGISTRY_SERVICE" envDefault:""`
+		// Repositories lists the repositories licenses may pull as comma separated
+		// pattern[=edition|edition...] entries, e.g. "sourcetool/*,sourcetool-ee/*=enterprise".
+		Repositories string `env:"REGISTRY_REPOSITORIES" envDefault:""`
+	}
+	Artifacts struct {
+		// Dir holds the release artifact files. Artifact paths are relative to it.
+		Dir string `env:"ARTIFACTS_DIR" envDefault:"artifacts"`
+	}
+	Bundle struct {
+		// Image is the container image installation bundles deploy.
+		Image string `env:"BUNDLE_IMAGE" envDefault:"sourcetool/sourcetool"`
+	}
+	Proxy struct {
+		// Trusted lists, comma separated, the IPs or CIDR ranges of reverse proxies
+		// whose X-Forwarded-For header is trusted to carry the client's address.
+		Trusted string `env:"TRUSTED_PROXIES" envDefault:"127.0.0.1/32,::1/128"`
+	}
+	Heartbeat struct {
+		RetentionDays int `env:"HEARTBEAT_RETENTION_DAYS" envDefault:"7"`
+	}
 	Postgres struct {
 		User     string `env:"POSTGRES_USER"`
 		Password string `env:"POSTGRES_PASSWORD"`
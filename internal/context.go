@@ -8,7 +8,10 @@ import (
 
 type ctxKey string
 
-const ContextUserKey ctxKey = "user"
+const (
+	ContextUserKey    ctxKey = "user"
+	ContextLicenseKey ctxKey = "license"
+)
 
 func ContextUser(ctx context.Context) *core.User {
 	v, ok := ctx.Value(ContextUserKey).(*core.User)
@@ -17,3 +20,11 @@ func ContextUser(ctx context.Context) *core.User {
 	}
 	return v
 }
+
+func ContextLicense(ctx context.Context) *core.License {
+	v, ok := ctx.Value(ContextLicenseKey).(*core.License)
+	if !ok {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Activation is an on-prem Sourcetool instance running on a license.
+// The ID is handed to the instance as its instance ID.
+type Activation struct {
+	ID          uuid.UUID `db:"id"`
+	LicenseID   uuid.UUID `db:"license_id"`
+	Fingerprint string    `db:"fingerprint"`
+	Hostname    string    `db:"hostname"`
+	Version     string    `db:"version"`
+	// Offline is set for instances activated with an activation request file.
+	Offline       bool       `db:"offline"`
+	FirstSeenAt   time.Time  `db:"first_seen_at"`
+	LastSeenAt    time.Time  `db:"last_seen_at"`
+	DeactivatedAt *time.Time `db:"deactivated_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
+
+func (a *Activation) IsActive() bool {
+	return a.DeactivatedAt == nil
+}
+
+// IsStale reports whether an active instance stopped sending heartbeats. Offline
+// instances never send heartbeats, so they are never stale.
+func (a *Activation) IsStale(now time.Time) bool {
+	return a.IsActive() && !a.Offline && now.Sub(a.LastSeenAt) > HeartbeatStaleAfter
+}
+
+// CanActivate reports whether another instance fits within maxInstances.
+// A zero maxInstances means unlimited.
+func CanActivate(activeCount, maxInstances int) bool {
+	return maxInstances == 0 || activeCount < maxInstances
+}
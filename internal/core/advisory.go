@@ -0,0 +1,65 @@
+package core
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/semver"
+)
+
+const (
+	AdvisorySeverityLow      = "low"
+	AdvisorySeverityMedium   = "medium"
+	AdvisorySeverityHigh     = "high"
+	AdvisorySeverityCritical = "critical"
+)
+
+// SecurityAdvisory describes a vulnerability in a range of product versions.
+// Identifier is the public reference, such as a CVE or GHSA ID.
+type SecurityAdvisory struct {
+	ID          uuid.UUID `db:"id"`
+	Product     string    `db:"product"`
+	Identifier  string    `db:"identifier"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	Severity    string    `db:"severity"`
+	URL         string    `db:"url"`
+	// IntroducedVersion is the first affected version and FixedVersion the first
+	// version with the fix. An empty bound is open-ended.
+	IntroducedVersion string    `db:"introduced_version"`
+	FixedVersion      string    `db:"fixed_version"`
+	PublishedAt       time.Time `db:"published_at"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+// Affects reports whether v falls within the advisory's affected range. Bounds that
+// fail to parse are treated as open-ended, erring on the side of warning.
+func (a *SecurityAdvisory) Affects(v semver.Version) bool {
+	if introduced, err := semver.Parse(a.IntroducedVersion); err == nil && v.Compare(introduced) < 0 {
+		return false
+	}
+	if fixed, err := semver.Parse(a.FixedVersion); err == nil && v.Compare(fixed) >= 0 {
+		return false
+	}
+	return true
+}
+
+// UpdateCheck records a running instance asking whether a newer version exists.
+type UpdateCheck struct {
+	ID        uuid.UUID `db:"id"`
+	LicenseID uuid.UUID `db:"license_id"`
+	Product   string    `db:"product"`
+	Version   string    `db:"version"`
+	Channel   string    `db:"channel"`
+	IPAddress string    `db:"ip_address"`
+	UserAgent string    `db:"user_agent"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// VersionCount is the number of licenses that reported running a version.
+type VersionCount struct {
+	Version  string `db:"version"`
+	Licenses int64  `db:"licenses"`
+}
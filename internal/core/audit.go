@@ -0,0 +1,72 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type AuditAction string
+
+const (
+	AuditActionUserRegistered   AuditAction = "user.registered"
+	AuditActionUserLoggedIn     AuditAction = "user.logged_in"
+	AuditActionUserEmailUpdated AuditAction = "user.email_updated"
+
+	AuditActionOrganizationMemberRoleUpdated AuditAction = "organization.member_role_updated"
+
+	AuditActionLicenseViewed           AuditAction = "license.viewed"
+	AuditActionLicenseCreated          AuditAction = "license.created"
+	AuditActionLicenseFileDownloaded   AuditAction = "license.file_downloaded"
+	AuditActionLicenseKeyRotated       AuditAction = "license.key_rotated"
+	AuditActionLicenseActivatedOffline AuditAction = "license.activated_offline"
+	AuditActionLicenseExpired          AuditAction = "license.expired"
+	AuditActionLicenseTransferStarted  AuditAction = "license.transfer_started"
+	AuditActionLicenseTransferred      AuditAction = "license.transferred"
+	AuditActionLicenseLicenseeChanged  AuditAction = "license.licensee_changed"
+	AuditActionLicenseBundleDownloaded AuditAction = "license.bundle_downloaded"
+
+	AuditActionAdminUsersListed        AuditAction = "admin.users_listed"
+	AuditActionAdminUserViewed         AuditAction = "admin.user_viewed"
+	AuditActionAdminLicensesListed     AuditAction = "admin.licenses_listed"
+	AuditActionAdminLicenseViewed      AuditAction = "admin.license_viewed"
+	AuditActionAdminLicenseSuspended   AuditAction = "admin.license_suspended"
+	AuditActionAdminLicenseReactivated AuditAction = "admin.license_reactivated"
+	AuditActionAdminLicenseRevoked     AuditAction = "admin.license_revoked"
+	AuditActionAdminLicenseIssued      AuditAction = "admin.license_issued"
+	AuditActionAdminLicenseUpgraded    AuditAction = "admin.license_upgraded"
+	AuditActionAdminLicenseRenewed     AuditAction = "admin.license_renewed"
+	AuditActionAdminReleaseCreated     AuditAction = "admin.release_created"
+	AuditActionAdminReleaseUpdated     AuditAction = "admin.release_updated"
+	AuditActionAdminReleaseDeleted     AuditAction = "admin.release_deleted"
+	AuditActionAdminArtifactCreated    AuditAction = "admin.artifact_created"
+	AuditActionAdminArtifactDeleted    AuditAction = "admin.artifact_deleted"
+	AuditActionAdminAdvisoryCreated    AuditAction = "admin.advisory_created"
+	AuditActionAdminAdvisoryUpdated    AuditAction = "admin.advisory_updated"
+	AuditActionAdminAdvisoryDeleted    AuditAction = "admin.advisory_deleted"
+)
+
+const (
+	AuditTargetUser     = "user"
+	AuditTargetLicense  = "license"
+	AuditTargetRelease  = "release"
+	AuditTargetAdvisory = "advisory"
+)
+
+// AuditEvent is an append-only record of a security-relevant action. ActorUserID is
+// nil for actions not performed by a signed-in user.
+type AuditEvent struct {
+	ID             uuid.UUID       `db:"id"`
+	Seq            int64           `db:"seq"`
+	Action         AuditAction     `db:"action"`
+	ActorUserID    *uuid.UUID      `db:"actor_user_id"`
+	OrganizationID *uuid.UUID      `db:"organization_id"`
+	TargetType     string          `db:"target_type"`
+	TargetID       string          `db:"target_id"`
+	IPAddress      string          `db:"ip_address"`
+	UserAgent      string          `db:"user_agent"`
+	RequestID      string          `db:"request_id"`
+	Payload        json.RawMessage `db:"payload"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
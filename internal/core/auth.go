@@ -11,12 +11,14 @@ import (
 )
 
 const (
-	tokenExpiration          = time.Duration(60) * time.Minute
-	tokenExpirationDev       = time.Duration(365*24) * time.Hour
-	RefreshTokenExpiration   = time.Duration(30*24) * time.Hour
-	XSRFTokenExpiration      = time.Duration(30*24) * time.Hour
-	RefreshTokenMaxAgeBuffer = time.Duration(7*24) * time.Hour
-	TmpTokenExpiration       = time.Duration(30) * time.Minute
+	tokenExpiration           = time.Duration(60) * time.Minute
+	tokenExpirationDev        = time.Duration(365*24) * time.Hour
+	RefreshTokenExpiration    = time.Duration(30*24) * time.Hour
+	XSRFTokenExpiration       = time.Duration(30*24) * time.Hour
+	RefreshTokenMaxAgeBuffer  = time.Duration(7*24) * time.Hour
+	TmpTokenExpiration        = time.Duration(30) * time.Minute
+	InvitationExpiration      = time.Duration(7*24) * time.Hour
+	LicenseTransferExpiration = time.Duration(3*24) * time.Hour
 )
 
 func TokenExpiration() time.Duration {
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"strconv"
+	"time"
+)
+
+// EncryptionKey is a data key wrapped by a master key. Only the wrapped form is stored.
+type EncryptionKey struct {
+	Version    int    `db:"version"`
+	WrappedKey []byte `db:"wrapped_key"`
+	// AADBound is false for keys wrapped before AAD was introduced.
+	AADBound  bool      `db:"aad_bound"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AAD returns the associated data the data key is wrapped with. It ties the wrapped
+// key to its version, so it cannot be swapped into another version's row.
+func (k *EncryptionKey) AAD() []byte {
+	return []byte("encryption_key:version:" + strconv.Itoa(k.Version))
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	HeartbeatBucketSize = time.Hour
+
+	// HeartbeatStaleAfter is how long an active instance may go without a
+	// heartbeat before it is reported as having stopped.
+	HeartbeatStaleAfter = 24 * time.Hour
+)
+
+type Heartbeat struct {
+	ID            uuid.UUID `db:"id"`
+	ActivationID  uuid.UUID `db:"activation_id"`
+	BucketStart   time.Time `db:"bucket_start"`
+	Version       string    `db:"version"`
+	ActiveUsers   int       `db:"active_users"`
+	UptimeSeconds int64     `db:"uptime_seconds"`
+	ReceivedAt    time.Time `db:"received_at"`
+}
+
+func HeartbeatBucket(t time.Time) time.Time {
+	return t.UTC().Truncate(HeartbeatBucketSize)
+}
+
+// DailyUsage aggregates the heartbeats of one instance over a UTC day.
+type DailyUsage struct {
+	Day              time.Time `db:"day"`
+	HeartbeatCount   int       `db:"heartbeat_count"`
+	MaxActiveUsers   int       `db:"max_active_users"`
+	AvgActiveUsers   float64   `db:"avg_active_users"`
+	MaxUptimeSeconds int64     `db:"max_uptime_seconds"`
+}
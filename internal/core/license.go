@@ -5,20 +5,238 @@ import (
 	"crypto/sha256"
 	"encoding/base32"
 	"encoding/hex"
+	"errors"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+)
+
+// DefaultLicenseName is the name given to the license issued at signup.
+const DefaultLicenseName = "Default"
+
+type LicenseStatus string
+
+const (
+	LicenseStatusActive    LicenseStatus = "active"
+	LicenseStatusSuspended LicenseStatus = "suspended"
+	LicenseStatusExpired   LicenseStatus = "expired"
+	LicenseStatusRevoked   LicenseStatus = "revoked"
 )
 
 type License struct {
-	ID            uuid.UUID `db:"id"`
-	UserID        uuid.UUID `db:"user_id"`
-	KeyHash       string    `db:"key_hash"`
-	KeyCiphertext []byte    `db:"key_ciphertext"`
-	KeyNonce      []byte    `db:"key_nonce"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
+	ID             uuid.UUID `db:"id"`
+	OrganizationID uuid.UUID `db:"organization_id"`
+	// UserID is the member who requested the license. They are shown as the
+	// licensee and receive notices about the key.
+	UserID        uuid.UUID      `db:"user_id"`
+	PlanID        uuid.UUID      `db:"plan_id"`
+	Name          string         `db:"name"`
+	Labels        pq.StringArray `db:"labels"`
+	KeyHash       string         `db:"key_hash"`
+	KeyCiphertext []byte         `db:"key_ciphertext"`
+	KeyNonce      []byte         `db:"key_nonce"`
+	// KeyVersion identifies the encryption key that sealed KeyCiphertext and KeyNonce.
+	KeyVersion int `db:"key_version"`
+	// KeyAADBound reports whether the key was sealed with KeyAAD. Licenses created
+	// before binding was introduced are unbound until they are re-sealed.
+	KeyAADBound bool          `db:"key_aad_bound"`
+	Status      LicenseStatus `db:"status"`
+	// IsTrial is set on licenses issued at signup until they are upgraded.
+	IsTrial             bool       `db:"is_trial"`
+	TrialReminderSentAt *time.Time `db:"trial_reminder_sent_at"`
+	ExpiresAt           *time.Time `db:"expires_at"`
+	RenewedAt           *time.Time `db:"renewed_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+}
+
+// EffectiveStatus returns the state the license is in at now. The stored status
+// is not moved to expired when expires_at passes, so an active license past its
+// expiry is reported as expired here. Suspension and revocation take precedence.
+func (l *License) EffectiveStatus(now time.Time) LicenseStatus {
+	if l.Status == LicenseStatusActive && l.IsExpired(now) {
+		return LicenseStatusExpired
+	}
+	return l.Status
+}
+
+func (l *License) IsValid(now time.Time) bool {
+	return l.EffectiveStatus(now) == LicenseStatusActive
+}
+
+func (l *License) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// DaysUntilExpiry returns the number of whole days left before the license expires,
+// rounded up so that a license expiring later today reports 1. The second return
+// value is false for perpetual licenses.
+func (l *License) DaysUntilExpiry(now time.Time) (int, bool) {
+	if l.ExpiresAt == nil {
+		return 0, false
+	}
+	remaining := l.ExpiresAt.Sub(now)
+	if remaining <= 0 {
+		return 0, true
+	}
+	return int(math.Ceil(remaining.Hours() / 24)), true
+}
+
+// Renew extends the license until expiresAt. An expired license becomes active again;
+// suspended licenses stay suspended and revoked licenses cannot be renewed.
+func (l *License) Renew(expiresAt, now time.Time) error {
+	if l.Status == LicenseStatusRevoked {
+		return errors.New("revoked license cannot be renewed")
+	}
+	if !expiresAt.After(now) {
+		return errors.New("renewed expiry must be in the future")
+	}
+	if l.Status == LicenseStatusExpired {
+		l.Status = LicenseStatusActive
+	}
+	l.ExpiresAt = &expiresAt
+	l.RenewedAt = &now
+	return nil
+}
+
+// StartTrial turns the license into a trial that expires after length.
+func (l *License) StartTrial(length time.Duration, now time.Time) {
+	expiresAt := now.Add(length)
+	l.IsTrial = true
+	l.ExpiresAt = &expiresAt
+}
+
+// NeedsTrialReminder reports whether the trial ends within window and its holder
+// has not been reminded yet.
+func (l *License) NeedsTrialReminder(window time.Duration, now time.Time) bool {
+	if !l.IsTrial || l.TrialReminderSentAt != nil || !l.IsValid(now) {
+		return false
+	}
+	return l.ExpiresAt.Sub(now) <= window
+}
+
+// Expire moves an active license past its expiry to the expired status.
+func (l *License) Expire(now time.Time) error {
+	if l.Status != LicenseStatusActive {
+		return errors.New("only active licenses can expire")
+	}
+	if !l.IsExpired(now) {
+		return errors.New("license has not reached its expiry")
+	}
+	l.Status = LicenseStatusExpired
+	return nil
+}
+
+// UpgradeTrial converts a trial into a paid license on planID, valid until expiresAt
+// or forever if it is nil. The key is kept, so running instances need no changes.
+// An expired trial becomes active again; a suspended one stays suspended.
+func (l *License) UpgradeTrial(planID uuid.UUID, expiresAt *time.Time, now time.Time) error {
+	if !l.IsTrial {
+		return errors.New("license is not a trial")
+	}
+	if l.Status == LicenseStatusRevoked {
+		return errors.New("revoked license cannot be upgraded")
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return errors.New("upgraded expiry must be in the future")
+	}
+	if l.Status == LicenseStatusExpired {
+		l.Status = LicenseStatusActive
+	}
+	l.IsTrial = false
+	l.PlanID = planID
+	l.ExpiresAt = expiresAt
+	l.RenewedAt = &now
+	return nil
+}
+
+// Suspend temporarily disables the license. Revoked licenses cannot be suspended.
+func (l *License) Suspend() error {
+	switch l.Status {
+	case LicenseStatusRevoked:
+		return errors.New("revoked license cannot be suspended")
+	case LicenseStatusSuspended:
+		return errors.New("license is already suspended")
+	}
+	l.Status = LicenseStatusSuspended
+	return nil
+}
+
+// Reactivate lifts a suspension.
+func (l *License) Reactivate() error {
+	if l.Status != LicenseStatusSuspended {
+		return errors.New("only suspended licenses can be reactivated")
+	}
+	l.Status = LicenseStatusActive
+	return nil
+}
+
+// Revoke permanently invalidates the license. The license store adds its key to the
+// revocation list for offline installs.
+func (l *License) Revoke() error {
+	if l.Status == LicenseStatusRevoked {
+		return errors.New("license is already revoked")
+	}
+	l.Status = LicenseStatusRevoked
+	return nil
+}
+
+// Transfer hands the license over to userID in organizationID. The key must be
+// re-sealed afterwards, since KeyAAD includes the user ID.
+func (l *License) Transfer(organizationID, userID uuid.UUID) error {
+	if l.Status == LicenseStatusRevoked {
+		return errors.New("revoked license cannot be transferred")
+	}
+	if l.UserID == userID {
+		return errors.New("license already belongs to the user")
+	}
+	l.OrganizationID = organizationID
+	l.UserID = userID
+	return nil
+}
+
+// KeyAAD returns the associated data the license key is sealed with. It ties the
+// ciphertext to this license and its user, so copying it to another row makes it
+// undecryptable. The key must be re-sealed whenever either ID changes.
+func (l *License) KeyAAD() []byte {
+	return []byte("license:" + l.ID.String() + ":user:" + l.UserID.String())
+}
+
+type LicenseRevocationReason string
+
+const (
+	LicenseRevocationReasonRevoked LicenseRevocationReason = "revoked"
+	LicenseRevocationReasonRotated LicenseRevocationReason = "rotated"
+)
+
+// LicenseRevocation records a key that must no longer be accepted, either because
+// the whole license was revoked or because its key was rotated. TxID is the
+// transaction that inserted the row; together with Seq it orders the list for
+// clients syncing it.
+type LicenseRevocation struct {
+	ID        uuid.UUID               `db:"id"`
+	TxID      uint64                  `db:"txid"`
+	Seq       int64                   `db:"seq"`
+	LicenseID uuid.UUID               `db:"license_id"`
+	KeyHash   string                  `db:"key_hash"`
+	Reason    LicenseRevocationReason `db:"reason"`
+	RevokedAt time.Time               `db:"revoked_at"`
+}
+
+// Cursor returns the position just after rv in the revocation list.
+func (rv *LicenseRevocation) Cursor() LicenseRevocationCursor {
+	return LicenseRevocationCursor{TxID: rv.TxID, Seq: rv.Seq}
+}
+
+// LicenseRevocationCursor is a position in the revocation list. Ordering by
+// inserting transaction rather than by Seq alone means a row can never become
+// visible behind a cursor that has already been handed out.
+type LicenseRevocationCursor struct {
+	TxID uint64
+	Seq  int64
 }
 
 func GenerateLicenseKey() (plainKey, hashedKey string, err error) {
@@ -47,7 +265,7 @@ func GenerateLicenseKey() (plainKey, hashedKey string, err error) {
 }
 
 func HashLicenseKey(plainKey string) string {
-	stripped := strings.ReplaceAll(plainKey, "-", "")
+	stripped := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(plainKey), "-", ""))
 	h := sha256.Sum256([]byte(stripped))
 	return hex.EncodeToString(h[:])
 }
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Organization owns licenses so that they outlive any single member's account.
+type Organization struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner   OrganizationRole = "owner"
+	OrganizationRoleAdmin   OrganizationRole = "admin"
+	OrganizationRoleBilling OrganizationRole = "billing"
+	OrganizationRoleViewer  OrganizationRole = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionLicenseRead        Permission = "license:read"
+	PermissionLicenseWrite       Permission = "license:write"
+	PermissionBillingManage      Permission = "billing:manage"
+	PermissionOrganizationUpdate Permission = "organization:update"
+	PermissionMemberInvite       Permission = "member:invite"
+	PermissionMemberManage       Permission = "member:manage"
+)
+
+var rolePermissions = map[OrganizationRole][]Permission{
+	OrganizationRoleOwner: {
+		PermissionLicenseRead,
+		PermissionLicenseWrite,
+		PermissionBillingManage,
+		PermissionOrganizationUpdate,
+		PermissionMemberInvite,
+		PermissionMemberManage,
+	},
+	OrganizationRoleAdmin: {
+		PermissionLicenseRead,
+		PermissionLicenseWrite,
+		PermissionOrganizationUpdate,
+		PermissionMemberInvite,
+	},
+	OrganizationRoleBilling: {
+		PermissionLicenseRead,
+		PermissionBillingManage,
+	},
+	OrganizationRoleViewer: {
+		PermissionLicenseRead,
+	},
+}
+
+func (r OrganizationRole) Can(p Permission) bool {
+	for _, v := range rolePermissions[r] {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+type OrganizationMember struct {
+	ID             uuid.UUID        `db:"id"`
+	OrganizationID uuid.UUID        `db:"organization_id"`
+	UserID         uuid.UUID        `db:"user_id"`
+	Role           OrganizationRole `db:"role"`
+	CreatedAt      time.Time        `db:"created_at"`
+	UpdatedAt      time.Time        `db:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+)
+
+const (
+	PlanCodeStandard   = "standard"
+	PlanCodeEnterprise = "enterprise"
+
+	// DefaultPlanCode is the plan attached to licenses issued at signup.
+	DefaultPlanCode = PlanCodeStandard
+)
+
+const (
+	FeatureSSO              = "sso"
+	FeatureAuditLog         = "audit_log"
+	FeatureRBAC             = "rbac"
+	FeatureHighAvailability = "high_availability"
+)
+
+type Plan struct {
+	ID           uuid.UUID      `db:"id"`
+	Code         string         `db:"code"`
+	Name         string         `db:"name"`
+	Edition      string         `db:"edition"`
+	SeatLimit    int            `db:"seat_limit"`
+	MaxInstances int            `db:"max_instances"`
+	Features     pq.StringArray `db:"features"`
+	SupportLevel string         `db:"support_level"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+}
+
+// Entitlements is what a license grants, resolved from its plan.
+// A zero SeatLimit or MaxInstances means unlimited.
+type Entitlements struct {
+	Edition      string
+	SeatLimit    int
+	MaxInstances int
+	Features     []string
+	SupportLevel string
+}
+
+func (p *Plan) Entitlements() Entitlements {
+	return Entitlements{
+		Edition:      p.Edition,
+		SeatLimit:    p.SeatLimit,
+		MaxInstances: p.MaxInstances,
+		Features:     slices.Clone([]string(p.Features)),
+		SupportLevel: p.SupportLevel,
+	}
+}
+
+func (e Entitlements) HasFeature(feature string) bool {
+	return slices.Contains(e.Features, feature)
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+)
+
+// ProductSourcetool is the product installation bundles deploy.
+const ProductSourcetool = "sourcetool"
+
+const (
+	ReleaseChannelStable = "stable"
+	ReleaseChannelBeta   = "beta"
+)
+
+// DownloadURLExpiration is how long a signed artifact download URL stays valid.
+const DownloadURLExpiration = time.Duration(24) * time.Hour
+
+type Release struct {
+	ID      uuid.UUID `db:"id"`
+	Product string    `db:"product"`
+	Version string    `db:"version"`
+	Channel string    `db:"channel"`
+	Notes   string    `db:"notes"`
+	// Editions lists the plan editions entitled to the release. Empty means every edition.
+	Editions   pq.StringArray `db:"editions"`
+	ReleasedAt time.Time      `db:"released_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+}
+
+// IsEntitled reports whether a license granting e may download the release.
+func (r *Release) IsEntitled(e Entitlements) bool {
+	return len(r.Editions) == 0 || slices.Contains(r.Editions, e.Edition)
+}
+
+// ReleaseArtifact is a downloadable file of a release, such as an installer or
+// archive. Path is relative to the configured artifacts directory.
+type ReleaseArtifact struct {
+	ID        uuid.UUID `db:"id"`
+	ReleaseID uuid.UUID `db:"release_id"`
+	Name      string    `db:"name"`
+	Path      string    `db:"path"`
+	Size      int64     `db:"size"`
+	SHA256    string    `db:"sha256"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ReleaseDownload records a request for an artifact made on a license. Range is the
+// requested byte range, empty for a full download.
+type ReleaseDownload struct {
+	ID         uuid.UUID `db:"id"`
+	ArtifactID uuid.UUID `db:"artifact_id"`
+	LicenseID  uuid.UUID `db:"license_id"`
+	Range      string    `db:"range"`
+	IPAddress  string    `db:"ip_address"`
+	UserAgent  string    `db:"user_agent"`
+	CreatedAt  time.Time `db:"created_at"`
+}
@@ -14,8 +14,10 @@ type User struct {
 	LastName         string    `db:"last_name"`
 	RefreshTokenHash string    `db:"refresh_token_hash"`
 	GoogleID         string    `db:"google_id"`
-	CreatedAt        time.Time `db:"created_at"`
-	UpdatedAt        time.Time `db:"updated_at"`
+	// IsStaff grants access to the /admin API. It can only be set from the database.
+	IsStaff   bool      `db:"is_staff"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
 func (u *User) FullName() string {
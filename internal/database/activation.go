@@ -0,0 +1,18 @@
+package database
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type ActivationStore interface {
+	GetByID(context.Context, uuid.UUID) (*core.Activation, error)
+	GetByLicenseIDAndFingerprint(context.Context, uuid.UUID, string) (*core.Activation, error)
+	ListByLicenseID(context.Context, uuid.UUID) ([]*core.Activation, error)
+	CountActiveByLicenseID(context.Context, uuid.UUID) (int, error)
+	Create(context.Context, *core.Activation) error
+	Update(context.Context, *core.Activation) error
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type AdvisoryStore interface {
+	GetByID(context.Context, uuid.UUID) (*core.SecurityAdvisory, error)
+	List(context.Context, ...AdvisoryQuery) ([]*core.SecurityAdvisory, error)
+	Count(context.Context, ...AdvisoryQuery) (int64, error)
+	Create(context.Context, *core.SecurityAdvisory) error
+	Update(context.Context, *core.SecurityAdvisory) error
+	Delete(context.Context, uuid.UUID) error
+}
+
+type AdvisoryQuery interface {
+	isAdvisoryQuery()
+}
+
+type AdvisoryByProductQuery struct {
+	Product string
+}
+
+func (q AdvisoryByProductQuery) isAdvisoryQuery() {}
+
+func AdvisoryByProduct(product string) AdvisoryQuery {
+	return AdvisoryByProductQuery{Product: product}
+}
+
+type AdvisoryLimitQuery struct {
+	Limit uint64
+}
+
+func (q AdvisoryLimitQuery) isAdvisoryQuery() {}
+
+func AdvisoryLimit(limit uint64) AdvisoryQuery {
+	return AdvisoryLimitQuery{Limit: limit}
+}
+
+type AdvisoryOffsetQuery struct {
+	Offset uint64
+}
+
+func (q AdvisoryOffsetQuery) isAdvisoryQuery() {}
+
+func AdvisoryOffset(offset uint64) AdvisoryQuery {
+	return AdvisoryOffsetQuery{Offset: offset}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type AuditEventStore interface {
+	Create(context.Context, *core.AuditEvent) error
+	List(context.Context, ...AuditEventQuery) ([]*core.AuditEvent, error)
+	Count(context.Context, ...AuditEventQuery) (int64, error)
+}
+
+type AuditEventQuery interface {
+	isAuditEventQuery()
+}
+
+type AuditEventByActorUserIDQuery struct {
+	UserID uuid.UUID
+}
+
+func (q AuditEventByActorUserIDQuery) isAuditEventQuery() {}
+
+func AuditEventByActorUserID(userID uuid.UUID) AuditEventQuery {
+	return AuditEventByActorUserIDQuery{UserID: userID}
+}
+
+type AuditEventLimitQuery struct {
+	Limit uint64
+}
+
+func (q AuditEventLimitQuery) isAuditEventQuery() {}
+
+func AuditEventLimit(limit uint64) AuditEventQuery {
+	return AuditEventLimitQuery{Limit: limit}
+}
+
+type AuditEventOffsetQuery struct {
+	Offset uint64
+}
+
+func (q AuditEventOffsetQuery) isAuditEventQuery() {}
+
+func AuditEventOffset(offset uint64) AuditEventQuery {
+	return AuditEventOffsetQuery{Offset: offset}
+}
@@ -5,7 +5,15 @@ import (
 )
 
 type Stores interface {
+	Activation() ActivationStore
+	Advisory() AdvisoryStore
+	AuditEvent() AuditEventStore
+	EncryptionKey() EncryptionKeyStore
+	Heartbeat() HeartbeatStore
 	License() LicenseStore
+	Organization() OrganizationStore
+	Plan() PlanStore
+	Release() ReleaseStore
 	User() UserStore
 }
 
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"context"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type EncryptionKeyStore interface {
+	List(context.Context) ([]*core.EncryptionKey, error)
+	Create(context.Context, *core.EncryptionKey) error
+	Update(context.Context, *core.EncryptionKey) error
+}
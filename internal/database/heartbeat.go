@@ -0,0 +1,16 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type HeartbeatStore interface {
+	Create(context.Context, *core.Heartbeat) error
+	ListDailyUsage(ctx context.Context, activationID uuid.UUID, since time.Time) ([]*core.DailyUsage, error)
+	RollupBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 
@@ -9,6 +10,131 @@ import (
 )
 
 type LicenseStore interface {
-	GetByUserID(context.Context, uuid.UUID) (*core.License, error)
+	GetByID(context.Context, uuid.UUID) (*core.License, error)
+	GetByIDForUpdate(context.Context, uuid.UUID) (*core.License, error)
+	GetByKeyHash(context.Context, string) (*core.License, error)
+	List(context.Context, ...LicenseQuery) ([]*core.License, error)
+	Count(context.Context, ...LicenseQuery) (int64, error)
 	Create(context.Context, *core.License) error
+	Update(context.Context, *core.License) error
+	ListRevocations(ctx context.Context, since time.Time, after core.LicenseRevocationCursor, limit uint64) ([]*core.LicenseRevocation, error)
+}
+
+type LicenseQuery interface {
+	isLicenseQuery()
+}
+
+type LicenseByUserIDQuery struct {
+	UserID uuid.UUID
+}
+
+func (q LicenseByUserIDQuery) isLicenseQuery() {}
+
+func LicenseByUserID(userID uuid.UUID) LicenseQuery {
+	return LicenseByUserIDQuery{UserID: userID}
+}
+
+type LicenseByOrganizationIDQuery struct {
+	OrganizationID uuid.UUID
+}
+
+func (q LicenseByOrganizationIDQuery) isLicenseQuery() {}
+
+func LicenseByOrganizationID(organizationID uuid.UUID) LicenseQuery {
+	return LicenseByOrganizationIDQuery{OrganizationID: organizationID}
+}
+
+// LicenseByMemberUserIDQuery matches licenses owned by any organization the user is a member of.
+type LicenseByMemberUserIDQuery struct {
+	UserID uuid.UUID
+}
+
+func (q LicenseByMemberUserIDQuery) isLicenseQuery() {}
+
+func LicenseByMemberUserID(userID uuid.UUID) LicenseQuery {
+	return LicenseByMemberUserIDQuery{UserID: userID}
+}
+
+type LicenseByStatusQuery struct {
+	Status core.LicenseStatus
+}
+
+func (q LicenseByStatusQuery) isLicenseQuery() {}
+
+func LicenseByStatus(status core.LicenseStatus) LicenseQuery {
+	return LicenseByStatusQuery{Status: status}
+}
+
+type LicenseByIsTrialQuery struct {
+	IsTrial bool
+}
+
+func (q LicenseByIsTrialQuery) isLicenseQuery() {}
+
+func LicenseByIsTrial(isTrial bool) LicenseQuery {
+	return LicenseByIsTrialQuery{IsTrial: isTrial}
+}
+
+// LicenseExpiresByQuery matches licenses whose expiry is at or before Time.
+// Perpetual licenses never match.
+type LicenseExpiresByQuery struct {
+	Time time.Time
+}
+
+func (q LicenseExpiresByQuery) isLicenseQuery() {}
+
+func LicenseExpiresBy(t time.Time) LicenseQuery {
+	return LicenseExpiresByQuery{Time: t}
+}
+
+type LicenseTrialReminderNotSentQuery struct{}
+
+func (q LicenseTrialReminderNotSentQuery) isLicenseQuery() {}
+
+func LicenseTrialReminderNotSent() LicenseQuery {
+	return LicenseTrialReminderNotSentQuery{}
+}
+
+// LicenseBySearchQuery matches licenses whose name, requesting user's email or
+// organization name contains Term.
+type LicenseBySearchQuery struct {
+	Term string
+}
+
+func (q LicenseBySearchQuery) isLicenseQuery() {}
+
+func LicenseBySearch(term string) LicenseQuery {
+	return LicenseBySearchQuery{Term: term}
+}
+
+// LicenseNeedsResealQuery matches licenses sealed with a key other than KeyVersion
+// or without associated data.
+type LicenseNeedsResealQuery struct {
+	KeyVersion int
+}
+
+func (q LicenseNeedsResealQuery) isLicenseQuery() {}
+
+func LicenseNeedsReseal(keyVersion int) LicenseQuery {
+	return LicenseNeedsResealQuery{KeyVersion: keyVersion}
+}
+
+type LicenseLimitQuery struct {
+	Limit uint64
+}
+
+func (q LicenseLimitQuery) isLicenseQuery() {}
+
+func LicenseLimit(limit uint64) LicenseQuery {
+	return LicenseLimitQuery{Limit: limit}
+}
+
+type LicenseOffsetQuery struct {
+	Offset uint64
+}
+
+func (q LicenseOffsetQuery) isLicenseQuery() {}
+
+func LicenseOffset(offset uint64) LicenseQuery {
+	return LicenseOffsetQuery{Offset: offset}
 }
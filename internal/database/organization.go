@@ -0,0 +1,21 @@
+package database
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type OrganizationStore interface {
+	GetByID(context.Context, uuid.UUID) (*core.Organization, error)
+	ListByUserID(context.Context, uuid.UUID) ([]*core.Organization, error)
+	Create(context.Context, *core.Organization) error
+	Update(context.Context, *core.Organization) error
+
+	GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*core.OrganizationMember, error)
+	ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*core.OrganizationMember, error)
+	CreateMember(context.Context, *core.OrganizationMember) error
+	UpdateMember(context.Context, *core.OrganizationMember) error
+}
@@ -0,0 +1,15 @@
+package database
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type PlanStore interface {
+	GetByID(context.Context, uuid.UUID) (*core.Plan, error)
+	GetByCode(context.Context, string) (*core.Plan, error)
+	List(context.Context) ([]*core.Plan, error)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type ReleaseStore interface {
+	GetByID(context.Context, uuid.UUID) (*core.Release, error)
+	List(context.Context, ...ReleaseQuery) ([]*core.Release, error)
+	Count(context.Context, ...ReleaseQuery) (int64, error)
+	Create(context.Context, *core.Release) error
+	Update(context.Context, *core.Release) error
+	Delete(context.Context, uuid.UUID) error
+
+	GetArtifact(ctx context.Context, releaseID, artifactID uuid.UUID) (*core.ReleaseArtifact, error)
+	ListArtifacts(ctx context.Context, releaseIDs ...uuid.UUID) ([]*core.ReleaseArtifact, error)
+	CreateArtifact(context.Context, *core.ReleaseArtifact) error
+	DeleteArtifact(ctx context.Context, releaseID, artifactID uuid.UUID) error
+
+	CreateDownload(context.Context, *core.ReleaseDownload) error
+
+	CreateUpdateCheck(context.Context, *core.UpdateCheck) error
+	// CountVersions counts the licenses that reported each version of product in
+	// update checks since the given time.
+	CountVersions(ctx context.Context, product string, since time.Time) ([]*core.VersionCount, error)
+}
+
+type ReleaseQuery interface {
+	isReleaseQuery()
+}
+
+type ReleaseByProductQuery struct {
+	Product string
+}
+
+func (q ReleaseByProductQuery) isReleaseQuery() {}
+
+func ReleaseByProduct(product string) ReleaseQuery {
+	return ReleaseByProductQuery{Product: product}
+}
+
+type ReleaseByChannelQuery struct {
+	Channel string
+}
+
+func (q ReleaseByChannelQuery) isReleaseQuery() {}
+
+func ReleaseByChannel(channel string) ReleaseQuery {
+	return ReleaseByChannelQuery{Channel: channel}
+}
+
+// ReleaseByEditionQuery matches releases a license of Edition is entitled to.
+type ReleaseByEditionQuery struct {
+	Edition string
+}
+
+func (q ReleaseByEditionQuery) isReleaseQuery() {}
+
+func ReleaseByEdition(edition string) ReleaseQuery {
+	return ReleaseByEditionQuery{Edition: edition}
+}
+
+type ReleaseLimitQuery struct {
+	Limit uint64
+}
+
+func (q ReleaseLimitQuery) isReleaseQuery() {}
+
+func ReleaseLimit(limit uint64) ReleaseQuery {
+	return ReleaseLimitQuery{Limit: limit}
+}
+
+type ReleaseOffsetQuery struct {
+	Offset uint64
+}
+
+func (q ReleaseOffsetQuery) isReleaseQuery() {}
+
+func ReleaseOffset(offset uint64) ReleaseQuery {
+	return ReleaseOffsetQuery{Offset: offset}
+}
@@ -16,4 +16,41 @@ type UserStore interface {
 	Create(context.Context, *core.User) error
 	Update(context.Context, *core.User) error
 	IsEmailExists(context.Context, string) (bool, error)
+	List(context.Context, ...UserQuery) ([]*core.User, error)
+	Count(context.Context, ...UserQuery) (int64, error)
+}
+
+type UserQuery interface {
+	isUserQuery()
+}
+
+// UserBySearchQuery matches users whose email or name contains Term.
+type UserBySearchQuery struct {
+	Term string
+}
+
+func (q UserBySearchQuery) isUserQuery() {}
+
+func UserBySearch(term string) UserQuery {
+	return UserBySearchQuery{Term: term}
+}
+
+type UserLimitQuery struct {
+	Limit uint64
+}
+
+func (q UserLimitQuery) isUserQuery() {}
+
+func UserLimit(limit uint64) UserQuery {
+	return UserLimitQuery{Limit: limit}
+}
+
+type UserOffsetQuery struct {
+	Offset uint64
+}
+
+func (q UserOffsetQuery) isUserQuery() {}
+
+func UserOffset(offset uint64) UserQuery {
+	return UserOffsetQuery{Offset: offset}
 }
@@ -1,48 +1,93 @@
 package encrypt
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"encoding/base64"
 	"errors"
-
-	"github.com/trysourcetool/onprem-portal/internal/config"
+	"fmt"
 )
 
+// Encryptor is a keyring of versioned AES-GCM keys loaded from a KeyProvider. New data
+// is always sealed with the primary key, while data sealed with any known key can still
+// be opened, so the primary key can be rotated without making existing ciphertexts unreadable.
 type Encryptor struct {
-	gcm cipher.AEAD
+	primary int
+	keys    map[int]cipher.AEAD
+}
+
+func NewEncryptor(ctx context.Context, provider KeyProvider) (*Encryptor, error) {
+	primary, previous, err := provider.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Encryptor{
+		primary: primary.Version,
+		keys:    make(map[int]cipher.AEAD),
+	}
+	for _, k := range append([]Key{primary}, previous...) {
+		if err := e.addKey(k); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
 }
 
-func NewEncryptor() (*Encryptor, error) {
-	keyB64 := config.Config.EncryptionKey
-	if keyB64 == "" {
-		return nil, errors.New("ENCRYPTION_KEY not set")
+func (e *Encryptor) addKey(k Key) error {
+	if k.Version <= 0 {
+		return errors.New("encryption key version must be positive")
 	}
-	key, err := base64.StdEncoding.DecodeString(keyB64)
-	if err != nil || len(key) != 32 {
-		return nil, errors.New("key must be 32byte base64")
+	if _, ok := e.keys[k.Version]; ok {
+		return fmt.Errorf("duplicate encryption key version %d", k.Version)
 	}
-	block, err := aes.NewCipher(key)
+	if len(k.Material) != keySize {
+		return errors.New("key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(k.Material)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &Encryptor{gcm: gcm}, nil
+	e.keys[k.Version] = gcm
+	return nil
+}
+
+// PrimaryVersion returns the version of the key used by Encrypt.
+func (e *Encryptor) PrimaryVersion() int {
+	return e.primary
 }
 
-func (e *Encryptor) Encrypt(plain []byte) (nonce, cipherText []byte, err error) {
-	nonce = make([]byte, e.gcm.NonceSize())
+func (e *Encryptor) Encrypt(plain []byte) (version int, nonce, cipherText []byte, err error) {
+	return e.EncryptWithAAD(plain, nil)
+}
+
+func (e *Encryptor) Decrypt(version int, nonce, cipherText []byte) ([]byte, error) {
+	return e.DecryptWithAAD(version, nonce, cipherText, nil)
+}
+
+// EncryptWithAAD seals plain and authenticates aad along with it. The ciphertext can
+// only be opened by passing the same aad, which binds it to the context it was created for.
+func (e *Encryptor) EncryptWithAAD(plain, aad []byte) (version int, nonce, cipherText []byte, err error) {
+	gcm := e.keys[e.primary]
+	nonce = make([]byte, gcm.NonceSize())
 	if _, err = rand.Read(nonce); err != nil {
 		return
 	}
-	cipherText = e.gcm.Seal(nil, nonce, plain, nil)
+	version = e.primary
+	cipherText = gcm.Seal(nil, nonce, plain, aad)
 	return
 }
 
-func (e *Encryptor) Decrypt(nonce, cipherText []byte) ([]byte, error) {
-	return e.gcm.Open(nil, nonce, cipherText, nil)
+func (e *Encryptor) DecryptWithAAD(version int, nonce, cipherText, aad []byte) ([]byte, error) {
+	gcm, ok := e.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key version %d", version)
+	}
+	return gcm.Open(nil, nonce, cipherText, aad)
 }
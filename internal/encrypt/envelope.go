@@ -0,0 +1,181 @@
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+)
+
+// KeyWrapper wraps and unwraps data keys with a master key that never leaves it.
+// An HSM or cloud KMS can be used by implementing this interface. The associated
+// data must be authenticated, and may be nil for keys wrapped without it.
+type KeyWrapper interface {
+	Wrap(ctx context.Context, dataKey, aad []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrappedKey, aad []byte) ([]byte, error)
+}
+
+type localKeyWrapper struct {
+	gcm cipher.AEAD
+}
+
+// NewLocalKeyWrapper wraps data keys with AES-GCM under a base64 master key.
+func NewLocalKeyWrapper(masterKeyB64 string) (KeyWrapper, error) {
+	if masterKeyB64 == "" {
+		return nil, errors.New("ENCRYPTION_MASTER_KEY not set")
+	}
+	k, err := decodeKey(0, masterKeyB64)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k.Material)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &localKeyWrapper{gcm: gcm}, nil
+}
+
+// Wrap returns the nonce followed by the sealed data key.
+func (w *localKeyWrapper) Wrap(ctx context.Context, dataKey, aad []byte) ([]byte, error) {
+	nonce := make([]byte, w.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return w.gcm.Seal(nonce, nonce, dataKey, aad), nil
+}
+
+func (w *localKeyWrapper) Unwrap(ctx context.Context, wrappedKey, aad []byte) ([]byte, error) {
+	n := w.gcm.NonceSize()
+	if len(wrappedKey) < n {
+		return nil, errors.New("wrapped key too short")
+	}
+	return w.gcm.Open(nil, wrappedKey[:n], wrappedKey[n:], aad)
+}
+
+type envelopeKeyProvider struct {
+	db      database.DB
+	wrapper KeyWrapper
+}
+
+// NewEnvelopeKeyProvider loads the data keys stored in Postgres and unwraps them with
+// wrapper. The highest version is the primary key.
+func NewEnvelopeKeyProvider(db database.DB, wrapper KeyWrapper) KeyProvider {
+	return &envelopeKeyProvider{db: db, wrapper: wrapper}
+}
+
+func (p *envelopeKeyProvider) Keys(ctx context.Context) (Key, []Key, error) {
+	wrapped, err := p.db.EncryptionKey().List(ctx)
+	if err != nil {
+		return Key{}, nil, err
+	}
+	if len(wrapped) == 0 {
+		return Key{}, nil, errors.New("no data keys stored; create one with `db create-data-key`")
+	}
+
+	keys := make([]Key, 0, len(wrapped))
+	for _, ek := range wrapped {
+		var aad []byte
+		if ek.AADBound {
+			aad = ek.AAD()
+		}
+		material, err := p.wrapper.Unwrap(ctx, ek.WrappedKey, aad)
+		if err != nil {
+			return Key{}, nil, fmt.Errorf("failed to unwrap data key version %d: %w", ek.Version, err)
+		}
+		keys = append(keys, Key{Version: ek.Version, Material: material})
+	}
+
+	// The store lists keys by ascending version.
+	last := len(keys) - 1
+	return keys[last], keys[:last], nil
+}
+
+// StoreDataKey wraps dataKey and stores it under version, or the next version if version
+// is zero. The highest version becomes the primary key once the portal is restarted.
+// A nil dataKey generates a random one.
+func StoreDataKey(ctx context.Context, db database.DB, wrapper KeyWrapper, version int, dataKey []byte) (int, error) {
+	if dataKey == nil {
+		dataKey = make([]byte, keySize)
+		if _, err := rand.Read(dataKey); err != nil {
+			return 0, err
+		}
+	}
+	if len(dataKey) != keySize {
+		return 0, errors.New("data key must be 32 bytes")
+	}
+
+	if err := db.WithTx(ctx, func(tx database.Tx) error {
+		if version == 0 {
+			existing, err := tx.EncryptionKey().List(ctx)
+			if err != nil {
+				return err
+			}
+			version = 1
+			if len(existing) > 0 {
+				version = existing[len(existing)-1].Version + 1
+			}
+		}
+
+		// The version is only known now, and is bound to the wrapped key as AAD.
+		k := &core.EncryptionKey{Version: version, AADBound: true}
+		var err error
+		k.WrappedKey, err = wrapper.Wrap(ctx, dataKey, k.AAD())
+		if err != nil {
+			return err
+		}
+
+		return tx.EncryptionKey().Create(ctx, k)
+	}); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// RewrapDataKeys re-wraps the data keys stored before AAD was introduced, binding each
+// to its version. It returns the number of keys re-wrapped.
+func RewrapDataKeys(ctx context.Context, db database.DB, wrapper KeyWrapper) (int, error) {
+	var n int
+	if err := db.WithTx(ctx, func(tx database.Tx) error {
+		keys, err := tx.EncryptionKey().List(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if k.AADBound {
+				continue
+			}
+
+			dataKey, err := wrapper.Unwrap(ctx, k.WrappedKey, nil)
+			if err != nil {
+				return fmt.Errorf("failed to unwrap data key version %d: %w", k.Version, err)
+			}
+			k.WrappedKey, err = wrapper.Wrap(ctx, dataKey, k.AAD())
+			if err != nil {
+				return err
+			}
+			k.AADBound = true
+
+			if err := tx.EncryptionKey().Update(ctx, k); err != nil {
+				return err
+			}
+			n++
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
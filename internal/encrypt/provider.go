@@ -0,0 +1,156 @@
+package encrypt
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+)
+
+const (
+	KeyProviderEnv      = "env"
+	KeyProviderFile     = "file"
+	KeyProviderEnvelope = "envelope"
+)
+
+const keySize = 32
+
+// Key is a versioned AES-256 data key.
+type Key struct {
+	Version  int
+	Material []byte
+}
+
+// KeyProvider supplies the data keys for an Encryptor. The primary key seals new data;
+// previous keys are only used to open data sealed before a rotation.
+type KeyProvider interface {
+	Keys(ctx context.Context) (primary Key, previous []Key, err error)
+}
+
+// NewKeyProvider returns the provider selected by ENCRYPTION_KEY_PROVIDER.
+func NewKeyProvider(db database.DB) (KeyProvider, error) {
+	cfg := config.Config.Encryption
+	switch cfg.KeyProvider {
+	case KeyProviderEnv:
+		return NewEnvKeyProvider(), nil
+	case KeyProviderFile:
+		return NewFileKeyProvider(cfg.KeyFile), nil
+	case KeyProviderEnvelope:
+		wrapper, err := NewLocalKeyWrapper(cfg.MasterKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewEnvelopeKeyProvider(db, wrapper), nil
+	default:
+		return nil, fmt.Errorf("unsupported encryption key provider %q", cfg.KeyProvider)
+	}
+}
+
+type envKeyProvider struct{}
+
+// NewEnvKeyProvider reads the primary key from ENCRYPTION_KEY and ENCRYPTION_KEY_VERSION
+// and previous keys from ENCRYPTION_PREVIOUS_KEYS.
+func NewEnvKeyProvider() KeyProvider {
+	return envKeyProvider{}
+}
+
+func (envKeyProvider) Keys(ctx context.Context) (Key, []Key, error) {
+	cfg := config.Config.Encryption
+	if cfg.Key == "" {
+		return Key{}, nil, errors.New("ENCRYPTION_KEY not set")
+	}
+
+	primary, err := decodeKey(cfg.KeyVersion, cfg.Key)
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	var previous []Key
+	for _, entry := range strings.Split(cfg.PreviousKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		k, err := parseVersionedKey(entry)
+		if err != nil {
+			return Key{}, nil, err
+		}
+		previous = append(previous, k)
+	}
+
+	return primary, previous, nil
+}
+
+type fileKeyProvider struct {
+	path string
+}
+
+// NewFileKeyProvider reads keys from a file, such as a mounted Docker or Kubernetes
+// secret. Each non-empty line holds a version:key pair and the first line is the
+// primary key. A file holding a single bare key is treated as ENCRYPTION_KEY_VERSION.
+func NewFileKeyProvider(path string) KeyProvider {
+	return fileKeyProvider{path: path}
+}
+
+func (p fileKeyProvider) Keys(ctx context.Context) (Key, []Key, error) {
+	if p.path == "" {
+		return Key{}, nil, errors.New("ENCRYPTION_KEY_FILE not set")
+	}
+
+	b, err := os.ReadFile(p.path)
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return Key{}, nil, fmt.Errorf("no keys in %s", p.path)
+	}
+
+	if len(lines) == 1 && !strings.Contains(lines[0], ":") {
+		k, err := decodeKey(config.Config.Encryption.KeyVersion, lines[0])
+		return k, nil, err
+	}
+
+	keys := make([]Key, 0, len(lines))
+	for _, line := range lines {
+		k, err := parseVersionedKey(line)
+		if err != nil {
+			return Key{}, nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys[0], keys[1:], nil
+}
+
+func parseVersionedKey(s string) (Key, error) {
+	v, keyB64, ok := strings.Cut(s, ":")
+	if !ok {
+		return Key{}, errors.New("encryption keys must be version:key pairs")
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid encryption key version %q", v)
+	}
+	return decodeKey(version, keyB64)
+}
+
+func decodeKey(version int, keyB64 string) (Key, error) {
+	key, err := base64.StdEncoding.DecodeString(keyB64)
+	if err != nil || len(key) != keySize {
+		return Key{}, errors.New("key must be 32byte base64")
+	}
+	return Key{Version: version, Material: key}, nil
+}
@@ -14,12 +14,24 @@ var (
 	ErrDatabase               = Status("database_error", 500)
 	ErrPermissionDenied       = Status("permission_denied", 403)
 	ErrInvalidArgument        = Status("invalid_argument", 400)
+	ErrNotFound               = Status("not_found", 404)
 	ErrAlreadyExists          = Status("already_exists", 409)
 	ErrUnauthenticated        = Status("unauthenticated", 401)
 	ErrResend                 = Status("resend_error", 500)
 	ErrUserNotFound           = Status("user_not_found", 404)
 	ErrUserEmailAlreadyExists = Status("user_email_already_exists", 409)
 	ErrLicenseNotFound        = Status("license_not_found", 404)
+	ErrLicenseNotActive       = Status("license_not_active", 403)
+	ErrPlanNotFound           = Status("plan_not_found", 404)
+	ErrActivationNotFound     = Status("activation_not_found", 404)
+	ErrActivationLimitReached = Status("activation_limit_reached", 403)
+
+	ErrOrganizationNotFound       = Status("organization_not_found", 404)
+	ErrOrganizationMemberNotFound = Status("organization_member_not_found", 404)
+
+	ErrReleaseNotFound         = Status("release_not_found", 404)
+	ErrReleaseArtifactNotFound = Status("release_artifact_not_found", 404)
+	ErrAdvisoryNotFound        = Status("advisory_not_found", 404)
 )
 
 type Meta []any
@@ -153,3 +165,27 @@ func IsUserNotFound(err error) bool {
 	}
 	return val.Title == "user_not_found"
 }
+
+func IsActivationNotFound(err error) bool {
+	val, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	return val.Title == "activation_not_found"
+}
+
+func IsOrganizationMemberNotFound(err error) bool {
+	val, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	return val.Title == "organization_member_not_found"
+}
+
+func IsLicenseNotFound(err error) bool {
+	val, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	return val.Title == "license_not_found"
+}
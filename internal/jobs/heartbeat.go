@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/logger"
+)
+
+// rollupHeartbeats folds raw heartbeats older than the retention window into
+// daily aggregates. The cutoff is aligned to a UTC day boundary so that a day is
+// normally rolled up in one pass.
+func (r *Runner) rollupHeartbeats(ctx context.Context, now time.Time) error {
+	cutoff := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -config.Config.Heartbeat.RetentionDays)
+
+	var removed int64
+	if err := r.db.WithTx(ctx, func(tx database.Tx) error {
+		var err error
+		removed, err = tx.Heartbeat().RollupBefore(ctx, cutoff)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		logger.Logger.Info("rolled up heartbeats", zap.Int64("removed", removed), zap.Time("cutoff", cutoff))
+	}
+
+	return nil
+}
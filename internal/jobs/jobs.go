@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/logger"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context, now time.Time) error
+}
+
+// Runner executes the portal's periodic background jobs. Every job must be safe
+// to run concurrently from several portal replicas.
+type Runner struct {
+	db database.DB
+}
+
+func NewRunner(db database.DB) *Runner {
+	return &Runner{db: db}
+}
+
+func (r *Runner) jobs() []job {
+	return []job{
+		{name: "rollup_heartbeats", interval: time.Hour, run: r.rollupHeartbeats},
+		{name: "remind_trials", interval: time.Hour, run: r.remindTrials},
+		{name: "expire_licenses", interval: 5 * time.Minute, run: r.expireLicenses},
+	}
+}
+
+// Run runs every job once immediately and then on its interval until ctx is canceled.
+func (r *Runner) Run(ctx context.Context) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, j := range r.jobs() {
+		eg.Go(func() error {
+			r.loop(ctx, j)
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, j job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.run(ctx, time.Now()); err != nil {
+			logger.Logger.Error("job failed", zap.String("job", j.name), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/zap"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/logger"
+	"github.com/trysourcetool/onprem-portal/internal/mail"
+)
+
+func buildPortalURL() (string, error) {
+	return internal.BuildURL(config.Config.BaseURL, "/", nil)
+}
+
+// remindTrials emails the holders of trials that end within the reminder window.
+// Each license is marked under lock before mailing, so a reminder is sent at most
+// once even when several replicas run the job.
+func (r *Runner) remindTrials(ctx context.Context, now time.Time) error {
+	window := time.Duration(config.Config.Trial.ReminderDays) * 24 * time.Hour
+
+	licenses, err := r.db.License().List(ctx,
+		database.LicenseByStatus(core.LicenseStatusActive),
+		database.LicenseByIsTrial(true),
+		database.LicenseTrialReminderNotSent(),
+		database.LicenseExpiresBy(now.Add(window)),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range licenses {
+		var claimed bool
+		if err := r.db.WithTx(ctx, func(tx database.Tx) error {
+			var err error
+			l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+			if err != nil {
+				return err
+			}
+			if !l.NeedsTrialReminder(window, now) {
+				return nil
+			}
+
+			l.TrialReminderSentAt = &now
+			claimed = true
+			return tx.License().Update(ctx, l)
+		}); err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		daysLeft, _ := l.DaysUntilExpiry(now)
+		if err := r.notifyLicensee(ctx, l, func(u *core.User, url string) error {
+			return mail.SendTrialEndingEmail(ctx, u.Email, u.FirstName, daysLeft, url)
+		}); err != nil {
+			logger.Logger.Error("failed to send trial reminder", zap.String("license_id", l.ID.String()), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// expireLicenses moves active licenses past their expiry to the expired status and
+// tells the holders of expired trials how to upgrade.
+func (r *Runner) expireLicenses(ctx context.Context, now time.Time) error {
+	licenses, err := r.db.License().List(ctx,
+		database.LicenseByStatus(core.LicenseStatusActive),
+		database.LicenseExpiresBy(now),
+	)
+	if err != nil {
+		return err
+	}
+
+	var expired int
+	for _, l := range licenses {
+		var claimed bool
+		if err := r.db.WithTx(ctx, func(tx database.Tx) error {
+			var err error
+			l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+			if err != nil {
+				return err
+			}
+			// Another replica may have expired it, or it may have been renewed since it was listed.
+			if err := l.Expire(now); err != nil {
+				return nil
+			}
+
+			if err := tx.License().Update(ctx, l); err != nil {
+				return err
+			}
+			claimed = true
+
+			return tx.AuditEvent().Create(ctx, &core.AuditEvent{
+				ID:             uuid.Must(uuid.NewV4()),
+				Action:         core.AuditActionLicenseExpired,
+				OrganizationID: &l.OrganizationID,
+				TargetType:     core.AuditTargetLicense,
+				TargetID:       l.ID.String(),
+				Payload:        []byte(`{}`),
+			})
+		}); err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		expired++
+
+		if !l.IsTrial {
+			continue
+		}
+		if err := r.notifyLicensee(ctx, l, func(u *core.User, url string) error {
+			return mail.SendTrialExpiredEmail(ctx, u.Email, u.FirstName, url)
+		}); err != nil {
+			logger.Logger.Error("failed to send trial expiry notice", zap.String("license_id", l.ID.String()), zap.Error(err))
+		}
+	}
+
+	if expired > 0 {
+		logger.Logger.Info("expired licenses", zap.Int("count", expired))
+	}
+
+	return nil
+}
+
+func (r *Runner) notifyLicensee(ctx context.Context, l *core.License, send func(u *core.User, url string) error) error {
+	u, err := r.db.User().GetByID(ctx, l.UserID)
+	if err != nil {
+		return err
+	}
+
+	url, err := buildPortalURL()
+	if err != nil {
+		return err
+	}
+
+	return send(u, url)
+}
@@ -4,6 +4,18 @@ import "github.com/golang-jwt/jwt/v5"
 
 const issuer = "https://portal.trysourcetool.com"
 
+// Audiences distinguish the kinds of token, which are all signed with JWT_KEY.
+const (
+	audienceAuth                   = "auth"
+	audienceMagicLink              = "magic_link"
+	audienceMagicLinkRegistration  = "magic_link_registration"
+	audienceGoogleAuthLink         = "google_auth_link"
+	audienceGoogleRegistration     = "google_registration"
+	audienceUpdateUserEmail        = "update_user_email"
+	audienceOrganizationInvitation = "organization_invitation"
+	audienceLicenseTransfer        = "license_transfer"
+)
+
 type AuthClaims struct {
 	XSRFToken string
 	jwt.RegisteredClaims
@@ -32,3 +44,16 @@ type UpdateUserEmailClaims struct {
 	Email string
 	jwt.RegisteredClaims
 }
+
+type OrganizationInvitationClaims struct {
+	OrganizationID string
+	Role           string
+	jwt.RegisteredClaims
+}
+
+type LicenseTransferClaims struct {
+	LicenseID          string
+	FromUserID         string
+	FromOrganizationID string
+	jwt.RegisteredClaims
+}
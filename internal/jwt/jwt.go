@@ -22,28 +22,40 @@ func signToken(claims jwt.Claims) (string, error) {
 	return token, nil
 }
 
+// parseToken verifies token and decodes it into claims. Every kind of token carries its
+// own audience, so that one kind cannot be passed off as another: they are all signed
+// with the same key, and several have an email address as the subject.
+func parseToken(token string, claims jwt.Claims, audience string) error {
+	if token == "" {
+		return errdefs.ErrInternal(errors.New("failed to get token"))
+	}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		return []byte(config.Config.Jwt.Key), nil
+	}, jwt.WithAudience(audience))
+	if err != nil {
+		return errdefs.ErrInternal(fmt.Errorf("failed to parse token: %s", err))
+	}
+
+	return nil
+}
+
 func SignAuthToken(userID, xsrfToken string, expiresAt time.Time) (string, error) {
 	return signToken(&AuthClaims{
 		XSRFToken: xsrfToken,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceAuth},
 			Subject:   userID,
 		},
 	})
 }
 
 func ParseAuthClaims(token string) (*AuthClaims, error) {
-	if token == "" {
-		return nil, errdefs.ErrInternal(errors.New("failed to get token"))
-	}
-
 	claims := &AuthClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.Config.Jwt.Key), nil
-	})
-	if err != nil {
-		return nil, errdefs.ErrInternal(fmt.Errorf("failed to parse token: %s", err))
+	if err := parseToken(token, claims, audienceAuth); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
@@ -54,22 +66,16 @@ func SignMagicLinkToken(email string) (string, error) {
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
 			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceMagicLink},
 			Subject:   email,
 		},
 	})
 }
 
 func ParseMagicLinkClaims(token string) (*MagicLinkClaims, error) {
-	if token == "" {
-		return nil, errdefs.ErrInternal(errors.New("failed to get token"))
-	}
-
 	claims := &MagicLinkClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.Config.Jwt.Key), nil
-	})
-	if err != nil {
-		return nil, errdefs.ErrInternal(fmt.Errorf("failed to parse token: %s", err))
+	if err := parseToken(token, claims, audienceMagicLink); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
@@ -79,6 +85,7 @@ func SignMagicLinkRegistrationToken(email string) (string, error) {
 	return signToken(MagicLinkRegistrationClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceMagicLinkRegistration},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
 			Subject:   email,
 		},
@@ -86,16 +93,9 @@ func SignMagicLinkRegistrationToken(email string) (string, error) {
 }
 
 func ParseMagicLinkRegistrationClaims(token string) (*MagicLinkRegistrationClaims, error) {
-	if token == "" {
-		return nil, errdefs.ErrInternal(errors.New("failed to get token"))
-	}
-
 	claims := &MagicLinkRegistrationClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.Config.Jwt.Key), nil
-	})
-	if err != nil {
-		return nil, errdefs.ErrInternal(err)
+	if err := parseToken(token, claims, audienceMagicLinkRegistration); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
@@ -106,21 +106,15 @@ func SignGoogleAuthLinkToken() (string, error) {
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
 			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceGoogleAuthLink},
 		},
 	})
 }
 
 func ParseGoogleAuthLinkClaims(token string) (*GoogleAuthLinkClaims, error) {
-	if token == "" {
-		return nil, errdefs.ErrInternal(errors.New("failed to get token"))
-	}
-
 	claims := &GoogleAuthLinkClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.Config.Jwt.Key), nil
-	})
-	if err != nil {
-		return nil, errdefs.ErrInternal(fmt.Errorf("failed to parse token: %s", err))
+	if err := parseToken(token, claims, audienceGoogleAuthLink); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
@@ -134,22 +128,16 @@ func SignGoogleRegistrationToken(googleID, email, firstName, lastName string) (s
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
 			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceGoogleRegistration},
 			Subject:   email,
 		},
 	})
 }
 
 func ParseGoogleRegistrationClaims(token string) (*GoogleRegistrationClaims, error) {
-	if token == "" {
-		return nil, errdefs.ErrInternal(errors.New("failed to get token"))
-	}
-
 	claims := &GoogleRegistrationClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.Config.Jwt.Key), nil
-	})
-	if err != nil {
-		return nil, errdefs.ErrInternal(fmt.Errorf("failed to parse token: %s", err))
+	if err := parseToken(token, claims, audienceGoogleRegistration); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
@@ -161,22 +149,61 @@ func SignUpdateUserEmailToken(userID, email string) (string, error) {
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceUpdateUserEmail},
 			Subject:   userID,
 		},
 	})
 }
 
 func ParseUpdateUserEmailClaims(token string) (*UpdateUserEmailClaims, error) {
-	if token == "" {
-		return nil, errdefs.ErrInternal(errors.New("failed to get token"))
+	claims := &UpdateUserEmailClaims{}
+	if err := parseToken(token, claims, audienceUpdateUserEmail); err != nil {
+		return nil, err
 	}
 
-	claims := &UpdateUserEmailClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		return []byte(config.Config.Jwt.Key), nil
+	return claims, nil
+}
+
+func SignOrganizationInvitationToken(organizationID, role, email string, expiresAt time.Time) (string, error) {
+	return signToken(&OrganizationInvitationClaims{
+		OrganizationID: organizationID,
+		Role:           role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceOrganizationInvitation},
+			Subject:   email,
+		},
 	})
-	if err != nil {
-		return nil, errdefs.ErrInternal(fmt.Errorf("failed to parse token: %s", err))
+}
+
+func ParseOrganizationInvitationClaims(token string) (*OrganizationInvitationClaims, error) {
+	claims := &OrganizationInvitationClaims{}
+	if err := parseToken(token, claims, audienceOrganizationInvitation); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func SignLicenseTransferToken(licenseID, fromUserID, fromOrganizationID, email string, expiresAt time.Time) (string, error) {
+	return signToken(&LicenseTransferClaims{
+		LicenseID:          licenseID,
+		FromUserID:         fromUserID,
+		FromOrganizationID: fromOrganizationID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audienceLicenseTransfer},
+			Subject:   email,
+		},
+	})
+}
+
+func ParseLicenseTransferClaims(token string) (*LicenseTransferClaims, error) {
+	claims := &LicenseTransferClaims{}
+	if err := parseToken(token, claims, audienceLicenseTransfer); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
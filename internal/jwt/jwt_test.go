@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/trysourcetool/onprem-portal/internal/config"
+)
+
+func TestMain(m *testing.M) {
+	for _, k := range []string{
+		"BASE_URL", "ENV", "LICENSE_SIGNING_KEY",
+		"POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB", "POSTGRES_HOST", "POSTGRES_PORT",
+		"GOOGLE_OAUTH_CLIENT_ID", "GOOGLE_OAUTH_CLIENT_SECRET",
+		"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_FROM_EMAIL",
+	} {
+		os.Setenv(k, "test")
+	}
+	os.Setenv("JWT_KEY", "test-jwt-key")
+	os.Setenv("SMTP_USE_TLS", "false")
+	config.Init()
+
+	os.Exit(m.Run())
+}
+
+type tokenKind struct {
+	name  string
+	sign  func() (string, error)
+	parse func(string) error
+}
+
+func tokenKinds() []tokenKind {
+	expiresAt := time.Now().Add(time.Hour)
+	const email = "user@example.com"
+
+	return []tokenKind{
+		{
+			name:  "auth",
+			sign:  func() (string, error) { return SignAuthToken("user-id", "xsrf", expiresAt) },
+			parse: func(tok string) error { _, err := ParseAuthClaims(tok); return err },
+		},
+		{
+			name:  "magic link",
+			sign:  func() (string, error) { return SignMagicLinkToken(email) },
+			parse: func(tok string) error { _, err := ParseMagicLinkClaims(tok); return err },
+		},
+		{
+			name:  "magic link registration",
+			sign:  func() (string, error) { return SignMagicLinkRegistrationToken(email) },
+			parse: func(tok string) error { _, err := ParseMagicLinkRegistrationClaims(tok); return err },
+		},
+		{
+			name:  "google auth link",
+			sign:  SignGoogleAuthLinkToken,
+			parse: func(tok string) error { _, err := ParseGoogleAuthLinkClaims(tok); return err },
+		},
+		{
+			name:  "google registration",
+			sign:  func() (string, error) { return SignGoogleRegistrationToken("google-id", email, "First", "Last") },
+			parse: func(tok string) error { _, err := ParseGoogleRegistrationClaims(tok); return err },
+		},
+		{
+			name:  "update user email",
+			sign:  func() (string, error) { return SignUpdateUserEmailToken("user-id", email) },
+			parse: func(tok string) error { _, err := ParseUpdateUserEmailClaims(tok); return err },
+		},
+		{
+			name:  "organization invitation",
+			sign:  func() (string, error) { return SignOrganizationInvitationToken("org-id", "viewer", email, expiresAt) },
+			parse: func(tok string) error { _, err := ParseOrganizationInvitationClaims(tok); return err },
+		},
+		{
+			name: "license transfer",
+			sign: func() (string, error) {
+				return SignLicenseTransferToken("license-id", "user-id", "org-id", email, expiresAt)
+			},
+			parse: func(tok string) error { _, err := ParseLicenseTransferClaims(tok); return err },
+		},
+	}
+}
+
+func TestTokenKindsAreNotInterchangeable(t *testing.T) {
+	kinds := tokenKinds()
+	for _, signer := range kinds {
+		tok, err := signer.sign()
+		if err != nil {
+			t.Fatalf("sign %s token: %v", signer.name, err)
+		}
+
+		for _, parser := range kinds {
+			err := parser.parse(tok)
+			switch {
+			case signer.name == parser.name && err != nil:
+				t.Errorf("%s token rejected by its own parser: %v", signer.name, err)
+			case signer.name != parser.name && err == nil:
+				t.Errorf("%s token accepted by the %s parser", signer.name, parser.name)
+			}
+		}
+	}
+}
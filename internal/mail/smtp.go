@@ -178,3 +178,165 @@ The Sourcetool Team`,
 		Body:     content,
 	})
 }
+
+func SendLicenseKeyRotatedEmail(ctx context.Context, to, firstName string) error {
+	subject := "[Sourcetool] Your license key has been rotated"
+	content := fmt.Sprintf(`Hi %s,
+
+The license key for your Sourcetool On-premise installation was just rotated. The previous key has been invalidated and will no longer be accepted.
+
+Please sign in to the Sourcetool On-premise portal to copy your new key, then update the LICENSE_KEY setting of every Sourcetool server that uses it.
+
+If you didn't rotate your key, please contact us immediately.
+
+Regards,
+
+The Sourcetool Team`,
+		firstName,
+	)
+
+	return send(ctx, input{
+		From:     config.Config.SMTP.FromEmail,
+		FromName: fromName,
+		To:       []string{to},
+		Subject:  subject,
+		Body:     content,
+	})
+}
+
+func SendLicenseTransferEmail(ctx context.Context, to, senderName, licenseName, url string) error {
+	subject := fmt.Sprintf("[Sourcetool] %s wants to transfer a license to you", senderName)
+	content := fmt.Sprintf(`Hi there,
+
+%s wants to transfer the Sourcetool On-premise license "%s" to you. Once you accept, you will become its licensee and receive all notices about its key.
+
+Please sign in to the Sourcetool On-premise portal, creating an account with this email address if you don't have one yet, and click the following link within the next 3 days to accept the transfer:
+%s
+
+If you weren't expecting this transfer, you can safely ignore this email.
+
+Regards,
+
+The Sourcetool Team`,
+		senderName,
+		licenseName,
+		url,
+	)
+
+	return send(ctx, input{
+		From:     config.Config.SMTP.FromEmail,
+		FromName: fromName,
+		To:       []string{to},
+		Subject:  subject,
+		Body:     content,
+	})
+}
+
+func SendLicenseTransferredEmail(ctx context.Context, to, firstName, licenseName, fromEmail, toEmail string) error {
+	subject := "[Sourcetool] A license has been transferred"
+	content := fmt.Sprintf(`Hi %s,
+
+The Sourcetool On-premise license "%s" has been transferred from %s to %s. The license key is unchanged, so Sourcetool servers that use it keep working.
+
+If you didn't expect this transfer, please contact us immediately.
+
+Regards,
+
+The Sourcetool Team`,
+		firstName,
+		licenseName,
+		fromEmail,
+		toEmail,
+	)
+
+	return send(ctx, input{
+		From:     config.Config.SMTP.FromEmail,
+		FromName: fromName,
+		To:       []string{to},
+		Subject:  subject,
+		Body:     content,
+	})
+}
+
+func SendTrialEndingEmail(ctx context.Context, to, firstName string, daysLeft int, url string) error {
+	subject := "[Sourcetool] Your trial is ending soon"
+	content := fmt.Sprintf(`Hi %s,
+
+Your Sourcetool On-premise trial ends in %d day(s). Once it ends, your Sourcetool servers will stop accepting the trial license key.
+
+To keep using Sourcetool without interruption, please contact us to upgrade. Your license key stays the same after upgrading, so no changes to your servers are needed.
+
+You can review your license at any time in the Sourcetool On-premise portal:
+%s
+
+Regards,
+
+The Sourcetool Team`,
+		firstName,
+		daysLeft,
+		url,
+	)
+
+	return send(ctx, input{
+		From:     config.Config.SMTP.FromEmail,
+		FromName: fromName,
+		To:       []string{to},
+		Subject:  subject,
+		Body:     content,
+	})
+}
+
+func SendTrialExpiredEmail(ctx context.Context, to, firstName, url string) error {
+	subject := "[Sourcetool] Your trial has ended"
+	content := fmt.Sprintf(`Hi %s,
+
+Your Sourcetool On-premise trial has ended and its license key is no longer accepted.
+
+Please contact us to upgrade. Your existing license key will start working again as soon as the upgrade is complete.
+
+You can review your license in the Sourcetool On-premise portal:
+%s
+
+Regards,
+
+The Sourcetool Team`,
+		firstName,
+		url,
+	)
+
+	return send(ctx, input{
+		From:     config.Config.SMTP.FromEmail,
+		FromName: fromName,
+		To:       []string{to},
+		Subject:  subject,
+		Body:     content,
+	})
+}
+
+func SendOrganizationInvitationEmail(ctx context.Context, to, inviterName, organizationName, url string) error {
+	subject := fmt.Sprintf("[Sourcetool] %s invited you to join %s", inviterName, organizationName)
+	content := fmt.Sprintf(`Hi there,
+
+%s has invited you to join %s on the Sourcetool On-premise portal, where you can manage its licenses together.
+
+Please click the following link within the next 7 days to accept the invitation:
+%s
+
+If you weren't expecting this invitation, you can safely ignore this email.
+
+Regards,
+
+The Sourcetool Team`,
+		inviterName,
+		organizationName,
+		url,
+	)
+
+	return send(ctx, input{
+		From:     config.Config.SMTP.FromEmail,
+		FromName: fromName,
+		To:       []string{to},
+		Subject:  subject,
+		Body:     content,
+	})
+}
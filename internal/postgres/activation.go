@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.ActivationStore = (*activationStore)(nil)
+
+type activationStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newActivationStore(db internal.DB) *activationStore {
+	return &activationStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *activationStore) GetByID(ctx context.Context, id uuid.UUID) (*core.Activation, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"activation" a`).
+		Where(sq.Eq{`a."id"`: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var a core.Activation
+	if err := s.db.GetContext(ctx, &a, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrActivationNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (s *activationStore) GetByLicenseIDAndFingerprint(ctx context.Context, licenseID uuid.UUID, fingerprint string) (*core.Activation, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"activation" a`).
+		Where(sq.Eq{
+			`a."license_id"`:  licenseID,
+			`a."fingerprint"`: fingerprint,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var a core.Activation
+	if err := s.db.GetContext(ctx, &a, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrActivationNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (s *activationStore) ListByLicenseID(ctx context.Context, licenseID uuid.UUID) ([]*core.Activation, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"activation" a`).
+		Where(sq.Eq{`a."license_id"`: licenseID}).
+		OrderBy(`a."first_seen_at"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	activations := make([]*core.Activation, 0)
+	if err := s.db.SelectContext(ctx, &activations, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return activations, nil
+}
+
+func (s *activationStore) CountActiveByLicenseID(ctx context.Context, licenseID uuid.UUID) (int, error) {
+	query, args, err := s.builder.
+		Select(`COUNT(*)`).
+		From(`"activation" a`).
+		Where(sq.Eq{
+			`a."license_id"`:     licenseID,
+			`a."deactivated_at"`: nil,
+		}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := s.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return count, nil
+}
+
+func (s *activationStore) Create(ctx context.Context, a *core.Activation) error {
+	if _, err := s.builder.
+		Insert(`"activation"`).
+		Columns(
+			`"id"`,
+			`"license_id"`,
+			`"fingerprint"`,
+			`"hostname"`,
+			`"version"`,
+			`"offline"`,
+			`"first_seen_at"`,
+			`"last_seen_at"`,
+		).
+		Values(
+			a.ID,
+			a.LicenseID,
+			a.Fingerprint,
+			a.Hostname,
+			a.Version,
+			a.Offline,
+			a.FirstSeenAt,
+			a.LastSeenAt,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *activationStore) Update(ctx context.Context, a *core.Activation) error {
+	if _, err := s.builder.
+		Update(`"activation"`).
+		Set(`"hostname"`, a.Hostname).
+		Set(`"version"`, a.Version).
+		Set(`"offline"`, a.Offline).
+		Set(`"last_seen_at"`, a.LastSeenAt).
+		Set(`"deactivated_at"`, a.DeactivatedAt).
+		Where(sq.Eq{`"id"`: a.ID}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *activationStore) columns() []string {
+	return []string{
+		`a."id"`,
+		`a."license_id"`,
+		`a."fingerprint"`,
+		`a."hostname"`,
+		`a."version"`,
+		`a."offline"`,
+		`a."first_seen_at"`,
+		`a."last_seen_at"`,
+		`a."deactivated_at"`,
+		`a."created_at"`,
+		`a."updated_at"`,
+	}
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.AdvisoryStore = (*advisoryStore)(nil)
+
+type advisoryStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newAdvisoryStore(db internal.DB) *advisoryStore {
+	return &advisoryStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *advisoryStore) GetByID(ctx context.Context, id uuid.UUID) (*core.SecurityAdvisory, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"security_advisory" sa`).
+		Where(sq.Eq{`sa."id"`: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var a core.SecurityAdvisory
+	if err := s.db.GetContext(ctx, &a, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrAdvisoryNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// List returns the advisories matching queries, most recently published first.
+func (s *advisoryStore) List(ctx context.Context, queries ...database.AdvisoryQuery) ([]*core.SecurityAdvisory, error) {
+	q := s.builder.
+		Select(s.columns()...).
+		From(`"security_advisory" sa`)
+
+	q = s.buildQuery(q, queries...)
+
+	query, args, err := q.
+		OrderBy(`sa."published_at" DESC`, `sa."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := make([]*core.SecurityAdvisory, 0)
+	if err := s.db.SelectContext(ctx, &advisories, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return advisories, nil
+}
+
+// Count returns the number of advisories matching queries, ignoring pagination.
+func (s *advisoryStore) Count(ctx context.Context, queries ...database.AdvisoryQuery) (int64, error) {
+	q := s.builder.
+		Select(`COUNT(*)`).
+		From(`"security_advisory" sa`)
+
+	query, args, err := s.buildQuery(q, queries...).
+		RemoveLimit().
+		RemoveOffset().
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := s.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return count, nil
+}
+
+func (s *advisoryStore) buildQuery(b sq.SelectBuilder, queries ...database.AdvisoryQuery) sq.SelectBuilder {
+	for _, q := range queries {
+		switch q := q.(type) {
+		case database.AdvisoryByProductQuery:
+			b = b.Where(sq.Eq{`sa."product"`: q.Product})
+		case database.AdvisoryLimitQuery:
+			b = b.Limit(q.Limit)
+		case database.AdvisoryOffsetQuery:
+			b = b.Offset(q.Offset)
+		}
+	}
+
+	return b
+}
+
+func (s *advisoryStore) Create(ctx context.Context, a *core.SecurityAdvisory) error {
+	if _, err := s.builder.
+		Insert(`"security_advisory"`).
+		Columns(
+			`"id"`,
+			`"product"`,
+			`"identifier"`,
+			`"title"`,
+			`"description"`,
+			`"severity"`,
+			`"url"`,
+			`"introduced_version"`,
+			`"fixed_version"`,
+			`"published_at"`,
+		).
+		Values(
+			a.ID,
+			a.Product,
+			a.Identifier,
+			a.Title,
+			a.Description,
+			a.Severity,
+			a.URL,
+			a.IntroducedVersion,
+			a.FixedVersion,
+			a.PublishedAt,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *advisoryStore) Update(ctx context.Context, a *core.SecurityAdvisory) error {
+	if _, err := s.builder.
+		Update(`"security_advisory"`).
+		Set(`"product"`, a.Product).
+		Set(`"identifier"`, a.Identifier).
+		Set(`"title"`, a.Title).
+		Set(`"description"`, a.Description).
+		Set(`"severity"`, a.Severity).
+		Set(`"url"`, a.URL).
+		Set(`"introduced_version"`, a.IntroducedVersion).
+		Set(`"fixed_version"`, a.FixedVersion).
+		Set(`"published_at"`, a.PublishedAt).
+		Where(sq.Eq{`"id"`: a.ID}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *advisoryStore) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.builder.
+		Delete(`"security_advisory"`).
+		Where(sq.Eq{`"id"`: id}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *advisoryStore) columns() []string {
+	return []string{
+		`sa."id"`,
+		`sa."product"`,
+		`sa."identifier"`,
+		`sa."title"`,
+		`sa."description"`,
+		`sa."severity"`,
+		`sa."url"`,
+		`sa."introduced_version"`,
+		`sa."fixed_version"`,
+		`sa."published_at"`,
+		`sa."created_at"`,
+		`sa."updated_at"`,
+	}
+}
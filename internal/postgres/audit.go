@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.AuditEventStore = (*auditEventStore)(nil)
+
+type auditEventStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newAuditEventStore(db internal.DB) *auditEventStore {
+	return &auditEventStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *auditEventStore) Create(ctx context.Context, e *core.AuditEvent) error {
+	payload := e.Payload
+	if payload == nil {
+		payload = []byte(`{}`)
+	}
+
+	if _, err := s.builder.
+		Insert(`"audit_event"`).
+		Columns(
+			`"id"`,
+			`"action"`,
+			`"actor_user_id"`,
+			`"organization_id"`,
+			`"target_type"`,
+			`"target_id"`,
+			`"ip_address"`,
+			`"user_agent"`,
+			`"request_id"`,
+			`"payload"`,
+		).
+		Values(
+			e.ID,
+			e.Action,
+			e.ActorUserID,
+			e.OrganizationID,
+			e.TargetType,
+			e.TargetID,
+			e.IPAddress,
+			e.UserAgent,
+			e.RequestID,
+			[]byte(payload),
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *auditEventStore) List(ctx context.Context, queries ...database.AuditEventQuery) ([]*core.AuditEvent, error) {
+	q := s.builder.
+		Select(s.columns()...).
+		From(`"audit_event" ae`)
+
+	q = s.buildQuery(q, queries...)
+
+	query, args, err := q.
+		OrderBy(`ae."seq" DESC`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*core.AuditEvent, 0)
+	if err := s.db.SelectContext(ctx, &events, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return events, nil
+}
+
+// Count returns the number of events matching queries, ignoring pagination.
+func (s *auditEventStore) Count(ctx context.Context, queries ...database.AuditEventQuery) (int64, error) {
+	q := s.builder.
+		Select(`COUNT(*)`).
+		From(`"audit_event" ae`)
+
+	query, args, err := s.buildQuery(q, queries...).
+		RemoveLimit().
+		RemoveOffset().
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := s.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return count, nil
+}
+
+func (s *auditEventStore) buildQuery(b sq.SelectBuilder, queries ...database.AuditEventQuery) sq.SelectBuilder {
+	for _, q := range queries {
+		switch q := q.(type) {
+		case database.AuditEventByActorUserIDQuery:
+			b = b.Where(sq.Eq{`ae."actor_user_id"`: q.UserID})
+		case database.AuditEventLimitQuery:
+			b = b.Limit(q.Limit)
+		case database.AuditEventOffsetQuery:
+			b = b.Offset(q.Offset)
+		}
+	}
+
+	return b
+}
+
+func (s *auditEventStore) columns() []string {
+	return []string{
+		`ae."id"`,
+		`ae."seq"`,
+		`ae."action"`,
+		`ae."actor_user_id"`,
+		`ae."organization_id"`,
+		`ae."target_type"`,
+		`ae."target_id"`,
+		`ae."ip_address"`,
+		`ae."user_agent"`,
+		`ae."request_id"`,
+		`ae."payload"`,
+		`ae."created_at"`,
+	}
+}
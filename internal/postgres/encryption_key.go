@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.EncryptionKeyStore = (*encryptionKeyStore)(nil)
+
+type encryptionKeyStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newEncryptionKeyStore(db internal.DB) *encryptionKeyStore {
+	return &encryptionKeyStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *encryptionKeyStore) List(ctx context.Context) ([]*core.EncryptionKey, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"encryption_key" ek`).
+		OrderBy(`ek."version"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*core.EncryptionKey, 0)
+	if err := s.db.SelectContext(ctx, &keys, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return keys, nil
+}
+
+func (s *encryptionKeyStore) Create(ctx context.Context, k *core.EncryptionKey) error {
+	if _, err := s.builder.
+		Insert(`"encryption_key"`).
+		Columns(
+			`"version"`,
+			`"wrapped_key"`,
+			`"aad_bound"`,
+		).
+		Values(
+			k.Version,
+			k.WrappedKey,
+			k.AADBound,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *encryptionKeyStore) Update(ctx context.Context, k *core.EncryptionKey) error {
+	if _, err := s.builder.
+		Update(`"encryption_key"`).
+		Set(`"wrapped_key"`, k.WrappedKey).
+		Set(`"aad_bound"`, k.AADBound).
+		Where(sq.Eq{`"version"`: k.Version}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *encryptionKeyStore) columns() []string {
+	return []string{
+		`ek."version"`,
+		`ek."wrapped_key"`,
+		`ek."aad_bound"`,
+		`ek."created_at"`,
+	}
+}
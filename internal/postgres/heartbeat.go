@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.HeartbeatStore = (*heartbeatStore)(nil)
+
+type heartbeatStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newHeartbeatStore(db internal.DB) *heartbeatStore {
+	return &heartbeatStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *heartbeatStore) Create(ctx context.Context, h *core.Heartbeat) error {
+	if _, err := s.builder.
+		Insert(`"instance_heartbeat"`).
+		Columns(
+			`"id"`,
+			`"activation_id"`,
+			`"bucket_start"`,
+			`"version"`,
+			`"active_users"`,
+			`"uptime_seconds"`,
+			`"received_at"`,
+		).
+		Values(
+			h.ID,
+			h.ActivationID,
+			h.BucketStart,
+			h.Version,
+			h.ActiveUsers,
+			h.UptimeSeconds,
+			h.ReceivedAt,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+// ListDailyUsage merges the rolled up days with the raw heartbeats that are still
+// inside the retention window, so the most recent days are included as well.
+func (s *heartbeatStore) ListDailyUsage(ctx context.Context, activationID uuid.UUID, since time.Time) ([]*core.DailyUsage, error) {
+	const query = `
+SELECT
+  u."day",
+  SUM(u."heartbeat_count")::INTEGER AS "heartbeat_count",
+  MAX(u."max_active_users") AS "max_active_users",
+  SUM(u."avg_active_users" * u."heartbeat_count") / SUM(u."heartbeat_count") AS "avg_active_users",
+  MAX(u."max_uptime_seconds") AS "max_uptime_seconds"
+FROM (
+  SELECT d."day", d."heartbeat_count", d."max_active_users", d."avg_active_users", d."max_uptime_seconds"
+  FROM "instance_usage_daily" d
+  WHERE d."activation_id" = $1 AND d."day" >= ($2::TIMESTAMPTZ AT TIME ZONE 'UTC')::DATE
+  UNION ALL
+  SELECT (h."bucket_start" AT TIME ZONE 'UTC')::DATE, COUNT(*), MAX(h."active_users"), AVG(h."active_users"), MAX(h."uptime_seconds")
+  FROM "instance_heartbeat" h
+  WHERE h."activation_id" = $1 AND h."bucket_start" >= $2
+  GROUP BY 1
+) u
+GROUP BY u."day"
+ORDER BY u."day"`
+
+	usage := make([]*core.DailyUsage, 0)
+	if err := s.db.SelectContext(ctx, &usage, query, activationID, since); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return usage, nil
+}
+
+// heartbeatRollupLockID is the advisory lock key that serializes rollups across portal replicas.
+const heartbeatRollupLockID = 7270001
+
+// RollupBefore folds raw heartbeats received before cutoff into instance_usage_daily
+// and deletes them. It returns the number of raw heartbeats removed. It must run
+// inside a transaction, which holds an advisory lock so that concurrent rollups
+// cannot count the same heartbeats twice.
+func (s *heartbeatStore) RollupBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	if _, err := s.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, heartbeatRollupLockID); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	const rollup = `
+INSERT INTO "instance_usage_daily" AS d
+  ("activation_id", "day", "heartbeat_count", "max_active_users", "avg_active_users", "max_uptime_seconds", "last_version")
+SELECT
+  h."activation_id",
+  (h."bucket_start" AT TIME ZONE 'UTC')::DATE,
+  COUNT(*),
+  MAX(h."active_users"),
+  AVG(h."active_users"),
+  MAX(h."uptime_seconds"),
+  (ARRAY_AGG(h."version" ORDER BY h."received_at" DESC))[1]
+FROM "instance_heartbeat" h
+WHERE h."received_at" < $1
+GROUP BY 1, 2
+ON CONFLICT ("activation_id", "day") DO UPDATE SET
+  "avg_active_users"   = (d."avg_active_users" * d."heartbeat_count" + EXCLUDED."avg_active_users" * EXCLUDED."heartbeat_count")
+                         / (d."heartbeat_count" + EXCLUDED."heartbeat_count"),
+  "heartbeat_count"    = d."heartbeat_count" + EXCLUDED."heartbeat_count",
+  "max_active_users"   = GREATEST(d."max_active_users", EXCLUDED."max_active_users"),
+  "max_uptime_seconds" = GREATEST(d."max_uptime_seconds", EXCLUDED."max_uptime_seconds"),
+  "last_version"       = EXCLUDED."last_version"`
+
+	if _, err := s.db.ExecContext(ctx, rollup, cutoff); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	res, err := s.builder.
+		Delete(`"instance_heartbeat"`).
+		Where(sq.Lt{`"received_at"`: cutoff}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return res.RowsAffected()
+}
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"strconv"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/gofrs/uuid/v5"
@@ -28,11 +30,11 @@ func newLicenseStore(db internal.DB) *licenseStore {
 	}
 }
 
-func (s *licenseStore) GetByUserID(ctx context.Context, userID uuid.UUID) (*core.License, error) {
+func (s *licenseStore) GetByID(ctx context.Context, id uuid.UUID) (*core.License, error) {
 	query, args, err := s.builder.
 		Select(s.columns()...).
 		From(`"license" l`).
-		Where(sq.Eq{`l."user_id"`: userID}).
+		Where(sq.Eq{`l."id"`: id}).
 		ToSql()
 	if err != nil {
 		return nil, err
@@ -49,22 +51,173 @@ func (s *licenseStore) GetByUserID(ctx context.Context, userID uuid.UUID) (*core
 	return &l, nil
 }
 
+// GetByIDForUpdate locks the license row until the surrounding transaction ends.
+func (s *licenseStore) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*core.License, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"license" l`).
+		Where(sq.Eq{`l."id"`: id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var l core.License
+	if err := s.db.GetContext(ctx, &l, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrLicenseNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (s *licenseStore) GetByKeyHash(ctx context.Context, keyHash string) (*core.License, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"license" l`).
+		Where(sq.Eq{`l."key_hash"`: keyHash}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var l core.License
+	if err := s.db.GetContext(ctx, &l, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrLicenseNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (s *licenseStore) List(ctx context.Context, queries ...database.LicenseQuery) ([]*core.License, error) {
+	q := s.builder.
+		Select(s.columns()...).
+		From(`"license" l`)
+
+	q = s.buildQuery(q, queries...)
+
+	query, args, err := q.
+		OrderBy(`l."created_at"`, `l."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	licenses := make([]*core.License, 0)
+	if err := s.db.SelectContext(ctx, &licenses, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return licenses, nil
+}
+
+// Count returns the number of licenses matching queries, ignoring pagination.
+func (s *licenseStore) Count(ctx context.Context, queries ...database.LicenseQuery) (int64, error) {
+	q := s.builder.
+		Select(`COUNT(*)`).
+		From(`"license" l`)
+
+	query, args, err := s.buildQuery(q, queries...).
+		RemoveLimit().
+		RemoveOffset().
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := s.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return count, nil
+}
+
+func (s *licenseStore) buildQuery(b sq.SelectBuilder, queries ...database.LicenseQuery) sq.SelectBuilder {
+	for _, q := range queries {
+		switch q := q.(type) {
+		case database.LicenseByUserIDQuery:
+			b = b.Where(sq.Eq{`l."user_id"`: q.UserID})
+		case database.LicenseByOrganizationIDQuery:
+			b = b.Where(sq.Eq{`l."organization_id"`: q.OrganizationID})
+		case database.LicenseByMemberUserIDQuery:
+			b = b.Where(
+				sq.Expr(`l."organization_id" IN (SELECT om."organization_id" FROM "organization_member" om WHERE om."user_id" = ?)`, q.UserID),
+			)
+		case database.LicenseByStatusQuery:
+			b = b.Where(sq.Eq{`l."status"`: q.Status})
+		case database.LicenseByIsTrialQuery:
+			b = b.Where(sq.Eq{`l."is_trial"`: q.IsTrial})
+		case database.LicenseExpiresByQuery:
+			b = b.Where(sq.LtOrEq{`l."expires_at"`: q.Time})
+		case database.LicenseTrialReminderNotSentQuery:
+			b = b.Where(sq.Eq{`l."trial_reminder_sent_at"`: nil})
+		case database.LicenseBySearchQuery:
+			pattern := likePattern(q.Term)
+			b = b.Where(sq.Or{
+				sq.ILike{`l."name"`: pattern},
+				sq.Expr(`l."user_id" IN (SELECT u."id" FROM "user" u WHERE u."email" ILIKE ?)`, pattern),
+				sq.Expr(`l."organization_id" IN (SELECT o."id" FROM "organization" o WHERE o."name" ILIKE ?)`, pattern),
+			})
+		case database.LicenseNeedsResealQuery:
+			b = b.Where(sq.Or{
+				sq.NotEq{`l."key_version"`: q.KeyVersion},
+				sq.Eq{`l."key_aad_bound"`: false},
+			})
+		case database.LicenseLimitQuery:
+			b = b.Limit(q.Limit)
+		case database.LicenseOffsetQuery:
+			b = b.Offset(q.Offset)
+		}
+	}
+
+	return b
+}
+
 func (s *licenseStore) Create(ctx context.Context, l *core.License) error {
 	if _, err := s.builder.
 		Insert(`"license"`).
 		Columns(
 			`"id"`,
+			`"organization_id"`,
 			`"user_id"`,
+			`"plan_id"`,
+			`"name"`,
+			`"labels"`,
 			`"key_hash"`,
 			`"key_ciphertext"`,
 			`"key_nonce"`,
+			`"key_version"`,
+			`"key_aad_bound"`,
+			`"status"`,
+			`"is_trial"`,
+			`"trial_reminder_sent_at"`,
+			`"expires_at"`,
+			`"renewed_at"`,
 		).
 		Values(
 			l.ID,
+			l.OrganizationID,
 			l.UserID,
+			l.PlanID,
+			l.Name,
+			l.Labels,
 			l.KeyHash,
 			l.KeyCiphertext,
 			l.KeyNonce,
+			l.KeyVersion,
+			l.KeyAADBound,
+			l.Status,
+			l.IsTrial,
+			l.TrialReminderSentAt,
+			l.ExpiresAt,
+			l.RenewedAt,
 		).
 		RunWith(s.db).
 		ExecContext(ctx); err != nil {
@@ -77,12 +230,130 @@ func (s *licenseStore) Create(ctx context.Context, l *core.License) error {
 	return nil
 }
 
+// Update persists l. When the key hash changes or the license transitions to
+// revoked, the superseded key is recorded in license_revocation so that it shows
+// up in the revocation list. Callers are expected to run this inside a transaction.
+func (s *licenseStore) Update(ctx context.Context, l *core.License) error {
+	prev, err := s.GetByIDForUpdate(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.builder.
+		Update(`"license"`).
+		Set(`"organization_id"`, l.OrganizationID).
+		Set(`"user_id"`, l.UserID).
+		Set(`"plan_id"`, l.PlanID).
+		Set(`"name"`, l.Name).
+		Set(`"labels"`, l.Labels).
+		Set(`"key_hash"`, l.KeyHash).
+		Set(`"key_ciphertext"`, l.KeyCiphertext).
+		Set(`"key_nonce"`, l.KeyNonce).
+		Set(`"key_version"`, l.KeyVersion).
+		Set(`"key_aad_bound"`, l.KeyAADBound).
+		Set(`"status"`, l.Status).
+		Set(`"is_trial"`, l.IsTrial).
+		Set(`"trial_reminder_sent_at"`, l.TrialReminderSentAt).
+		Set(`"expires_at"`, l.ExpiresAt).
+		Set(`"renewed_at"`, l.RenewedAt).
+		Where(sq.Eq{`"id"`: l.ID}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	if prev.KeyHash != l.KeyHash {
+		if err := s.createRevocation(ctx, l.ID, prev.KeyHash, core.LicenseRevocationReasonRotated); err != nil {
+			return err
+		}
+	}
+	if prev.Status != core.LicenseStatusRevoked && l.Status == core.LicenseStatusRevoked {
+		if err := s.createRevocation(ctx, l.ID, l.KeyHash, core.LicenseRevocationReasonRevoked); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *licenseStore) createRevocation(ctx context.Context, licenseID uuid.UUID, keyHash string, reason core.LicenseRevocationReason) error {
+	if _, err := s.builder.
+		Insert(`"license_revocation"`).
+		Columns(
+			`"id"`,
+			`"license_id"`,
+			`"key_hash"`,
+			`"reason"`,
+		).
+		Values(
+			uuid.Must(uuid.NewV4()),
+			licenseID,
+			keyHash,
+			reason,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+// ListRevocations only returns rows inserted by transactions older than every
+// transaction still in progress. Anything inserted later is ordered after them, so
+// a client resuming from the last row's cursor cannot skip an entry.
+func (s *licenseStore) ListRevocations(ctx context.Context, since time.Time, after core.LicenseRevocationCursor, limit uint64) ([]*core.LicenseRevocation, error) {
+	query, args, err := s.builder.
+		Select(
+			`lr."id"`,
+			`lr."txid"`,
+			`lr."seq"`,
+			`lr."license_id"`,
+			`lr."key_hash"`,
+			`lr."reason"`,
+			`lr."revoked_at"`,
+		).
+		From(`"license_revocation" lr`).
+		Where(sq.GtOrEq{`lr."revoked_at"`: since}).
+		Where(sq.Expr(`(lr."txid", lr."seq") > (?::xid8, ?)`, strconv.FormatUint(after.TxID, 10), after.Seq)).
+		Where(`lr."txid" < pg_snapshot_xmin(pg_current_snapshot())`).
+		OrderBy(`lr."txid"`, `lr."seq"`).
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := make([]*core.LicenseRevocation, 0)
+	if err := s.db.SelectContext(ctx, &revocations, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return revocations, nil
+}
+
 func (s *licenseStore) columns() []string {
 	return []string{
 		`l."id"`,
+		`l."organization_id"`,
 		`l."user_id"`,
+		`l."plan_id"`,
+		`l."name"`,
+		`l."labels"`,
 		`l."key_hash"`,
 		`l."key_ciphertext"`,
 		`l."key_nonce"`,
+		`l."key_version"`,
+		`l."key_aad_bound"`,
+		`l."status"`,
+		`l."is_trial"`,
+		`l."trial_reminder_sent_at"`,
+		`l."expires_at"`,
+		`l."renewed_at"`,
+		`l."created_at"`,
+		`l."updated_at"`,
 	}
 }
@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.OrganizationStore = (*organizationStore)(nil)
+
+type organizationStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newOrganizationStore(db internal.DB) *organizationStore {
+	return &organizationStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *organizationStore) GetByID(ctx context.Context, id uuid.UUID) (*core.Organization, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"organization" o`).
+		Where(sq.Eq{`o."id"`: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var o core.Organization
+	if err := s.db.GetContext(ctx, &o, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrOrganizationNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (s *organizationStore) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*core.Organization, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"organization" o`).
+		InnerJoin(`"organization_member" om ON om."organization_id" = o."id"`).
+		Where(sq.Eq{`om."user_id"`: userID}).
+		OrderBy(`om."created_at"`, `o."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]*core.Organization, 0)
+	if err := s.db.SelectContext(ctx, &organizations, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return organizations, nil
+}
+
+func (s *organizationStore) Create(ctx context.Context, o *core.Organization) error {
+	if _, err := s.builder.
+		Insert(`"organization"`).
+		Columns(
+			`"id"`,
+			`"name"`,
+		).
+		Values(
+			o.ID,
+			o.Name,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *organizationStore) Update(ctx context.Context, o *core.Organization) error {
+	if _, err := s.builder.
+		Update(`"organization"`).
+		Set(`"name"`, o.Name).
+		Where(sq.Eq{`"id"`: o.ID}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *organizationStore) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*core.OrganizationMember, error) {
+	query, args, err := s.builder.
+		Select(s.memberColumns()...).
+		From(`"organization_member" om`).
+		Where(sq.Eq{
+			`om."organization_id"`: organizationID,
+			`om."user_id"`:         userID,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var m core.OrganizationMember
+	if err := s.db.GetContext(ctx, &m, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrOrganizationMemberNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (s *organizationStore) ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*core.OrganizationMember, error) {
+	query, args, err := s.builder.
+		Select(s.memberColumns()...).
+		From(`"organization_member" om`).
+		Where(sq.Eq{`om."organization_id"`: organizationID}).
+		OrderBy(`om."created_at"`, `om."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*core.OrganizationMember, 0)
+	if err := s.db.SelectContext(ctx, &members, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return members, nil
+}
+
+func (s *organizationStore) CreateMember(ctx context.Context, m *core.OrganizationMember) error {
+	if _, err := s.builder.
+		Insert(`"organization_member"`).
+		Columns(
+			`"id"`,
+			`"organization_id"`,
+			`"user_id"`,
+			`"role"`,
+		).
+		Values(
+			m.ID,
+			m.OrganizationID,
+			m.UserID,
+			m.Role,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *organizationStore) UpdateMember(ctx context.Context, m *core.OrganizationMember) error {
+	if _, err := s.builder.
+		Update(`"organization_member"`).
+		Set(`"role"`, m.Role).
+		Where(sq.Eq{`"id"`: m.ID}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *organizationStore) columns() []string {
+	return []string{
+		`o."id"`,
+		`o."name"`,
+		`o."created_at"`,
+		`o."updated_at"`,
+	}
+}
+
+func (s *organizationStore) memberColumns() []string {
+	return []string{
+		`om."id"`,
+		`om."organization_id"`,
+		`om."user_id"`,
+		`om."role"`,
+		`om."created_at"`,
+		`om."updated_at"`,
+	}
+}
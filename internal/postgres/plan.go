@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.PlanStore = (*planStore)(nil)
+
+type planStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newPlanStore(db internal.DB) *planStore {
+	return &planStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *planStore) GetByID(ctx context.Context, id uuid.UUID) (*core.Plan, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"plan" p`).
+		Where(sq.Eq{`p."id"`: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var p core.Plan
+	if err := s.db.GetContext(ctx, &p, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrPlanNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (s *planStore) GetByCode(ctx context.Context, code string) (*core.Plan, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"plan" p`).
+		Where(sq.Eq{`p."code"`: code}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var p core.Plan
+	if err := s.db.GetContext(ctx, &p, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrPlanNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (s *planStore) List(ctx context.Context) ([]*core.Plan, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"plan" p`).
+		OrderBy(`p."created_at"`, `p."code"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]*core.Plan, 0)
+	if err := s.db.SelectContext(ctx, &plans, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return plans, nil
+}
+
+func (s *planStore) columns() []string {
+	return []string{
+		`p."id"`,
+		`p."code"`,
+		`p."name"`,
+		`p."edition"`,
+		`p."seat_limit"`,
+		`p."max_instances"`,
+		`p."features"`,
+		`p."support_level"`,
+		`p."created_at"`,
+		`p."updated_at"`,
+	}
+}
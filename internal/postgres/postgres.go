@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -39,10 +40,42 @@ func (db *db) WithTx(ctx context.Context, fn func(tx database.Tx) error) error {
 	return sqlxTx.Commit()
 }
 
+func (db *db) Activation() database.ActivationStore {
+	return newActivationStore(internal.NewQueryLogger(db.db))
+}
+
+func (db *db) Advisory() database.AdvisoryStore {
+	return newAdvisoryStore(internal.NewQueryLogger(db.db))
+}
+
+func (db *db) AuditEvent() database.AuditEventStore {
+	return newAuditEventStore(internal.NewQueryLogger(db.db))
+}
+
+func (db *db) EncryptionKey() database.EncryptionKeyStore {
+	return newEncryptionKeyStore(internal.NewQueryLogger(db.db))
+}
+
+func (db *db) Heartbeat() database.HeartbeatStore {
+	return newHeartbeatStore(internal.NewQueryLogger(db.db))
+}
+
 func (db *db) License() database.LicenseStore {
 	return newLicenseStore(internal.NewQueryLogger(db.db))
 }
 
+func (db *db) Organization() database.OrganizationStore {
+	return newOrganizationStore(internal.NewQueryLogger(db.db))
+}
+
+func (db *db) Plan() database.PlanStore {
+	return newPlanStore(internal.NewQueryLogger(db.db))
+}
+
+func (db *db) Release() database.ReleaseStore {
+	return newReleaseStore(internal.NewQueryLogger(db.db))
+}
+
 func (db *db) User() database.UserStore {
 	return newUserStore(internal.NewQueryLogger(db.db))
 }
@@ -53,10 +86,42 @@ type tx struct {
 	db *sqlx.Tx
 }
 
+func (t *tx) Activation() database.ActivationStore {
+	return newActivationStore(internal.NewQueryLogger(t.db))
+}
+
+func (t *tx) Advisory() database.AdvisoryStore {
+	return newAdvisoryStore(internal.NewQueryLogger(t.db))
+}
+
+func (t *tx) AuditEvent() database.AuditEventStore {
+	return newAuditEventStore(internal.NewQueryLogger(t.db))
+}
+
+func (t *tx) EncryptionKey() database.EncryptionKeyStore {
+	return newEncryptionKeyStore(internal.NewQueryLogger(t.db))
+}
+
+func (t *tx) Heartbeat() database.HeartbeatStore {
+	return newHeartbeatStore(internal.NewQueryLogger(t.db))
+}
+
 func (t *tx) License() database.LicenseStore {
 	return newLicenseStore(internal.NewQueryLogger(t.db))
 }
 
+func (t *tx) Organization() database.OrganizationStore {
+	return newOrganizationStore(internal.NewQueryLogger(t.db))
+}
+
+func (t *tx) Plan() database.PlanStore {
+	return newPlanStore(internal.NewQueryLogger(t.db))
+}
+
+func (t *tx) Release() database.ReleaseStore {
+	return newReleaseStore(internal.NewQueryLogger(t.db))
+}
+
 func (t *tx) User() database.UserStore {
 	return newUserStore(internal.NewQueryLogger(t.db))
 }
@@ -124,3 +189,10 @@ func Migrate(dir string) error {
 
 	return nil
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern builds a case-insensitive substring pattern for term, escaping LIKE wildcards.
+func likePattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
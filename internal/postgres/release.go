@@ -0,0 +1,386 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid/v5"
+	"github.com/lib/pq"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+var _ database.ReleaseStore = (*releaseStore)(nil)
+
+type releaseStore struct {
+	db      internal.DB
+	builder sq.StatementBuilderType
+}
+
+func newReleaseStore(db internal.DB) *releaseStore {
+	return &releaseStore{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *releaseStore) GetByID(ctx context.Context, id uuid.UUID) (*core.Release, error) {
+	query, args, err := s.builder.
+		Select(s.columns()...).
+		From(`"release" r`).
+		Where(sq.Eq{`r."id"`: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var r core.Release
+	if err := s.db.GetContext(ctx, &r, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrReleaseNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// List returns the releases matching queries, newest first.
+func (s *releaseStore) List(ctx context.Context, queries ...database.ReleaseQuery) ([]*core.Release, error) {
+	q := s.builder.
+		Select(s.columns()...).
+		From(`"release" r`)
+
+	q = s.buildQuery(q, queries...)
+
+	query, args, err := q.
+		OrderBy(`r."released_at" DESC`, `r."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]*core.Release, 0)
+	if err := s.db.SelectContext(ctx, &releases, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return releases, nil
+}
+
+// Count returns the number of releases matching queries, ignoring pagination.
+func (s *releaseStore) Count(ctx context.Context, queries ...database.ReleaseQuery) (int64, error) {
+	q := s.builder.
+		Select(`COUNT(*)`).
+		From(`"release" r`)
+
+	query, args, err := s.buildQuery(q, queries...).
+		RemoveLimit().
+		RemoveOffset().
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := s.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return count, nil
+}
+
+func (s *releaseStore) buildQuery(b sq.SelectBuilder, queries ...database.ReleaseQuery) sq.SelectBuilder {
+	for _, q := range queries {
+		switch q := q.(type) {
+		case database.ReleaseByProductQuery:
+			b = b.Where(sq.Eq{`r."product"`: q.Product})
+		case database.ReleaseByChannelQuery:
+			b = b.Where(sq.Eq{`r."channel"`: q.Channel})
+		case database.ReleaseByEditionQuery:
+			b = b.Where(sq.Or{
+				sq.Expr(`CARDINALITY(r."editions") = 0`),
+				sq.Expr(`? = ANY(r."editions")`, q.Edition),
+			})
+		case database.ReleaseLimitQuery:
+			b = b.Limit(q.Limit)
+		case database.ReleaseOffsetQuery:
+			b = b.Offset(q.Offset)
+		}
+	}
+
+	return b
+}
+
+func (s *releaseStore) Create(ctx context.Context, r *core.Release) error {
+	if _, err := s.builder.
+		Insert(`"release"`).
+		Columns(
+			`"id"`,
+			`"product"`,
+			`"version"`,
+			`"channel"`,
+			`"notes"`,
+			`"editions"`,
+			`"released_at"`,
+		).
+		Values(
+			r.ID,
+			r.Product,
+			r.Version,
+			r.Channel,
+			r.Notes,
+			r.Editions,
+			r.ReleasedAt,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *releaseStore) Update(ctx context.Context, r *core.Release) error {
+	if _, err := s.builder.
+		Update(`"release"`).
+		Set(`"product"`, r.Product).
+		Set(`"version"`, r.Version).
+		Set(`"channel"`, r.Channel).
+		Set(`"notes"`, r.Notes).
+		Set(`"editions"`, r.Editions).
+		Set(`"released_at"`, r.ReleasedAt).
+		Where(sq.Eq{`"id"`: r.ID}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+// Delete removes the release along with its artifact records. Files in the
+// artifacts directory are left in place.
+func (s *releaseStore) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.builder.
+		Delete(`"release"`).
+		Where(sq.Eq{`"id"`: id}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *releaseStore) GetArtifact(ctx context.Context, releaseID, artifactID uuid.UUID) (*core.ReleaseArtifact, error) {
+	query, args, err := s.builder.
+		Select(s.artifactColumns()...).
+		From(`"release_artifact" ra`).
+		Where(sq.Eq{
+			`ra."id"`:         artifactID,
+			`ra."release_id"`: releaseID,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var a core.ReleaseArtifact
+	if err := s.db.GetContext(ctx, &a, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errdefs.ErrReleaseArtifactNotFound(err)
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (s *releaseStore) ListArtifacts(ctx context.Context, releaseIDs ...uuid.UUID) ([]*core.ReleaseArtifact, error) {
+	artifacts := make([]*core.ReleaseArtifact, 0)
+	if len(releaseIDs) == 0 {
+		return artifacts, nil
+	}
+
+	query, args, err := s.builder.
+		Select(s.artifactColumns()...).
+		From(`"release_artifact" ra`).
+		Where(sq.Eq{`ra."release_id"`: releaseIDs}).
+		OrderBy(`ra."name"`, `ra."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SelectContext(ctx, &artifacts, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return artifacts, nil
+}
+
+func (s *releaseStore) CreateArtifact(ctx context.Context, a *core.ReleaseArtifact) error {
+	if _, err := s.builder.
+		Insert(`"release_artifact"`).
+		Columns(
+			`"id"`,
+			`"release_id"`,
+			`"name"`,
+			`"path"`,
+			`"size"`,
+			`"sha256"`,
+		).
+		Values(
+			a.ID,
+			a.ReleaseID,
+			a.Name,
+			a.Path,
+			a.Size,
+			a.SHA256,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errdefs.ErrAlreadyExists(err)
+		}
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *releaseStore) DeleteArtifact(ctx context.Context, releaseID, artifactID uuid.UUID) error {
+	if _, err := s.builder.
+		Delete(`"release_artifact"`).
+		Where(sq.Eq{
+			`"id"`:         artifactID,
+			`"release_id"`: releaseID,
+		}).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *releaseStore) CreateDownload(ctx context.Context, d *core.ReleaseDownload) error {
+	if _, err := s.builder.
+		Insert(`"release_download"`).
+		Columns(
+			`"id"`,
+			`"artifact_id"`,
+			`"license_id"`,
+			`"range"`,
+			`"ip_address"`,
+			`"user_agent"`,
+		).
+		Values(
+			d.ID,
+			d.ArtifactID,
+			d.LicenseID,
+			d.Range,
+			d.IPAddress,
+			d.UserAgent,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+func (s *releaseStore) CreateUpdateCheck(ctx context.Context, c *core.UpdateCheck) error {
+	if _, err := s.builder.
+		Insert(`"update_check"`).
+		Columns(
+			`"id"`,
+			`"license_id"`,
+			`"product"`,
+			`"version"`,
+			`"channel"`,
+			`"ip_address"`,
+			`"user_agent"`,
+		).
+		Values(
+			c.ID,
+			c.LicenseID,
+			c.Product,
+			c.Version,
+			c.Channel,
+			c.IPAddress,
+			c.UserAgent,
+		).
+		RunWith(s.db).
+		ExecContext(ctx); err != nil {
+		return errdefs.ErrDatabase(err)
+	}
+
+	return nil
+}
+
+// CountVersions orders versions by the number of licenses running them. A license
+// that upgraded during the period counts towards both versions.
+func (s *releaseStore) CountVersions(ctx context.Context, product string, since time.Time) ([]*core.VersionCount, error) {
+	query, args, err := s.builder.
+		Select(
+			`uc."version"`,
+			`COUNT(DISTINCT uc."license_id") AS "licenses"`,
+		).
+		From(`"update_check" uc`).
+		Where(sq.Eq{`uc."product"`: product}).
+		Where(sq.GtOrEq{`uc."created_at"`: since}).
+		GroupBy(`uc."version"`).
+		OrderBy(`"licenses" DESC`, `uc."version"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]*core.VersionCount, 0)
+	if err := s.db.SelectContext(ctx, &counts, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return counts, nil
+}
+
+func (s *releaseStore) columns() []string {
+	return []string{
+		`r."id"`,
+		`r."product"`,
+		`r."version"`,
+		`r."channel"`,
+		`r."notes"`,
+		`r."editions"`,
+		`r."released_at"`,
+		`r."created_at"`,
+		`r."updated_at"`,
+	}
+}
+
+func (s *releaseStore) artifactColumns() []string {
+	return []string{
+		`ra."id"`,
+		`ra."release_id"`,
+		`ra."name"`,
+		`ra."path"`,
+		`ra."size"`,
+		`ra."sha256"`,
+		`ra."created_at"`,
+		`ra."updated_at"`,
+	}
+}
@@ -122,6 +122,7 @@ func (s *userStore) Create(ctx context.Context, u *core.User) error {
 			`"last_name"`,
 			`"refresh_token_hash"`,
 			`"google_id"`,
+			`"is_staff"`,
 		).
 		Values(
 			u.ID,
@@ -130,6 +131,7 @@ func (s *userStore) Create(ctx context.Context, u *core.User) error {
 			u.LastName,
 			u.RefreshTokenHash,
 			u.GoogleID,
+			u.IsStaff,
 		).
 		RunWith(s.db).
 		ExecContext(ctx); err != nil {
@@ -150,6 +152,7 @@ func (s *userStore) Update(ctx context.Context, u *core.User) error {
 		Set(`"last_name"`, u.LastName).
 		Set(`"refresh_token_hash"`, u.RefreshTokenHash).
 		Set(`"google_id"`, u.GoogleID).
+		Set(`"is_staff"`, u.IsStaff).
 		Where(sq.Eq{`"id"`: u.ID}).
 		RunWith(s.db).
 		ExecContext(ctx); err != nil {
@@ -159,6 +162,70 @@ func (s *userStore) Update(ctx context.Context, u *core.User) error {
 	return nil
 }
 
+func (s *userStore) List(ctx context.Context, queries ...database.UserQuery) ([]*core.User, error) {
+	q := s.builder.
+		Select(s.columns()...).
+		From(`"user" u`)
+
+	q = s.buildQuery(q, queries...)
+
+	query, args, err := q.
+		OrderBy(`u."created_at"`, `u."id"`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*core.User, 0)
+	if err := s.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, errdefs.ErrDatabase(err)
+	}
+
+	return users, nil
+}
+
+// Count returns the number of users matching queries, ignoring pagination.
+func (s *userStore) Count(ctx context.Context, queries ...database.UserQuery) (int64, error) {
+	q := s.builder.
+		Select(`COUNT(*)`).
+		From(`"user" u`)
+
+	query, args, err := s.buildQuery(q, queries...).
+		RemoveLimit().
+		RemoveOffset().
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := s.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, errdefs.ErrDatabase(err)
+	}
+
+	return count, nil
+}
+
+func (s *userStore) buildQuery(b sq.SelectBuilder, queries ...database.UserQuery) sq.SelectBuilder {
+	for _, q := range queries {
+		switch q := q.(type) {
+		case database.UserBySearchQuery:
+			pattern := likePattern(q.Term)
+			b = b.Where(sq.Or{
+				sq.ILike{`u."email"`: pattern},
+				sq.ILike{`u."first_name"`: pattern},
+				sq.ILike{`u."last_name"`: pattern},
+			})
+		case database.UserLimitQuery:
+			b = b.Limit(q.Limit)
+		case database.UserOffsetQuery:
+			b = b.Offset(q.Offset)
+		}
+	}
+
+	return b
+}
+
 func (s *userStore) IsEmailExists(ctx context.Context, email string) (bool, error) {
 	if _, err := s.GetByEmail(ctx, email); err != nil {
 		if errdefs.IsUserNotFound(err) {
@@ -177,6 +244,7 @@ func (s *userStore) columns() []string {
 		`u."last_name"`,
 		`u."google_id"`,
 		`u."refresh_token_hash"`,
+		`u."is_staff"`,
 		`u."created_at"`,
 		`u."updated_at"`,
 	}
@@ -0,0 +1,240 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+// TokenExpiration is how long a registry token is valid. Docker requests a new one
+// whenever it expires, so it is kept short.
+const TokenExpiration = 5 * time.Minute
+
+const ActionPull = "pull"
+
+// Access is a resource and the actions granted on it, as carried in the token's
+// access claim.
+type Access struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Actions []string `json:"actions"`
+}
+
+// ParseScope parses a scope parameter such as "repository:sourcetool/sourcetool:pull,push".
+func ParseScope(scope string) (*Access, error) {
+	typ, rest, ok := strings.Cut(scope, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid scope %q", scope)
+	}
+	i := strings.LastIndex(rest, ":")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid scope %q", scope)
+	}
+	return &Access{
+		Type:    typ,
+		Name:    rest[:i],
+		Actions: strings.Split(rest[i+1:], ","),
+	}, nil
+}
+
+// Repository is a pattern of repositories, matched with path.Match, that licenses of
+// Editions may pull. Empty Editions means every edition.
+type Repository struct {
+	Pattern  string
+	Editions []string
+}
+
+// parseRepositories parses comma separated entries of the form pattern[=edition|edition...].
+func parseRepositories(s string) ([]Repository, error) {
+	var repos []Repository
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, editions, _ := strings.Cut(entry, "=")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+
+		repo := Repository{Pattern: pattern}
+		if editions != "" {
+			repo.Editions = strings.Split(editions, "|")
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+func (r Repository) matches(name string, e core.Entitlements) bool {
+	if ok, _ := path.Match(r.Pattern, name); !ok {
+		return false
+	}
+	return len(r.Editions) == 0 || slices.Contains(r.Editions, e.Edition)
+}
+
+// TokenIssuer issues Docker Registry v2 bearer tokens.
+type TokenIssuer struct {
+	privateKey   *ecdsa.PrivateKey
+	keyID        string
+	issuer       string
+	service      string
+	repositories []Repository
+}
+
+// NewTokenIssuer loads the issuer from REGISTRY_* settings. It returns nil when
+// REGISTRY_SIGNING_KEY is not set, which disables the token service.
+func NewTokenIssuer() (*TokenIssuer, error) {
+	cfg := config.Config.Registry
+	if cfg.SigningKey == "" {
+		return nil, nil
+	}
+
+	privateKey, err := parsePrivateKey(cfg.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	repositories, err := parseRepositories(cfg.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = config.Config.BaseURL
+	}
+
+	return &TokenIssuer{
+		privateKey:   privateKey,
+		keyID:        KeyID(&privateKey.PublicKey),
+		issuer:       issuer,
+		service:      cfg.Service,
+		repositories: repositories,
+	}, nil
+}
+
+// parsePrivateKey accepts an ECDSA P-256 key as PEM, or as base64 encoded PEM for
+// environments where multi-line values are inconvenient.
+func parsePrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	b := []byte(s)
+	if !strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN") {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, errors.New("registry signing key must be PEM or base64 encoded PEM")
+		}
+		b = decoded
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("registry signing key must be PEM or base64 encoded PEM")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve.Params().BitSize != 256 {
+		return nil, errors.New("registry signing key must be an ECDSA P-256 key")
+	}
+	return ecKey, nil
+}
+
+// KeyID returns the libtrust fingerprint of pub, which the registry uses to find the
+// certificate a token was signed with.
+func KeyID(pub *ecdsa.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(pub)
+	sum := sha256.Sum256(der)
+	encoded := strings.TrimRight(base32.StdEncoding.EncodeToString(sum[:30]), "=")
+
+	var buf bytes.Buffer
+	for i := 0; i < len(encoded); i += 4 {
+		if i > 0 {
+			buf.WriteByte(':')
+		}
+		buf.WriteString(encoded[i:min(i+4, len(encoded))])
+	}
+	return buf.String()
+}
+
+// Service returns the registry service name tokens are issued for. An empty name
+// accepts whatever service the client asks for.
+func (t *TokenIssuer) Service() string {
+	return t.service
+}
+
+// Authorize narrows the requested access to what a license granting e is entitled to.
+// Only pulls from matching repositories are granted; anything else is left out of the
+// token, which makes the registry reject it.
+func (t *TokenIssuer) Authorize(requested []*Access, e core.Entitlements) []*Access {
+	granted := make([]*Access, 0, len(requested))
+	for _, a := range requested {
+		if a.Type != "repository" || !slices.Contains(a.Actions, ActionPull) {
+			continue
+		}
+		if !slices.ContainsFunc(t.repositories, func(r Repository) bool { return r.matches(a.Name, e) }) {
+			continue
+		}
+		granted = append(granted, &Access{Type: a.Type, Name: a.Name, Actions: []string{ActionPull}})
+	}
+	return granted
+}
+
+type claims struct {
+	Access []*Access `json:"access"`
+	// Audience is a single string, which every registry version accepts.
+	Audience string `json:"aud"`
+	jwt.RegisteredClaims
+}
+
+// Issue signs a token for subject granting access on service.
+func (t *TokenIssuer) Issue(subject, service string, access []*Access, now time.Time) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodES256, &claims{
+		Access:   access,
+		Audience: service,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenExpiration)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        hex.EncodeToString(jti),
+		},
+	})
+	tok.Header["kid"] = t.keyID
+
+	return tok.SignedString(t.privateKey)
+}
@@ -0,0 +1,139 @@
+// Package semver parses and orders semantic versions as reported by on-prem
+// instances and published in the release catalog.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is dropped since it does not
+// affect precedence.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+}
+
+// Parse parses v as MAJOR.MINOR.PATCH with an optional -prerelease and +build suffix.
+// A leading "v" is accepted.
+func Parse(v string) (Version, error) {
+	s := strings.TrimPrefix(v, "v")
+	s, _, _ = strings.Cut(s, "+")
+	core, prerelease, hasPrerelease := strings.Cut(s, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q", v)
+	}
+	var nums [3]uint64
+	for i, p := range parts {
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return Version{}, fmt.Errorf("invalid version %q", v)
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q", v)
+		}
+		nums[i] = n
+	}
+
+	var ids []string
+	if hasPrerelease {
+		ids = strings.Split(prerelease, ".")
+		for _, id := range ids {
+			if id == "" {
+				return Version{}, fmt.Errorf("invalid version %q", v)
+			}
+		}
+	}
+
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Prerelease: ids}, nil
+}
+
+// IsPrerelease reports whether v is a pre-release such as 1.2.0-beta.1.
+func (v Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.IsPrerelease() {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 as v is lower than, equal to or higher than w.
+func (v Version) Compare(w Version) int {
+	if c := compareUint(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+
+	// A pre-release has lower precedence than the release it precedes.
+	switch {
+	case !v.IsPrerelease() && !w.IsPrerelease():
+		return 0
+	case !v.IsPrerelease():
+		return 1
+	case !w.IsPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(w.Prerelease); i++ {
+		if c := comparePrereleaseID(v.Prerelease[i], w.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(w.Prerelease)))
+}
+
+// comparePrereleaseID orders numeric identifiers numerically and below alphanumeric ones,
+// which are ordered lexically.
+func comparePrereleaseID(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		if c := compareUint(uint64(len(a)), uint64(len(b))); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+type activationResponse struct {
+	ID            string  `json:"id"`
+	LicenseID     string  `json:"licenseId"`
+	Fingerprint   string  `json:"fingerprint"`
+	Hostname      string  `json:"hostname"`
+	Version       string  `json:"version"`
+	Offline       bool    `json:"offline"`
+	Active        bool    `json:"active"`
+	Stale         bool    `json:"stale"`
+	FirstSeenAt   string  `json:"firstSeenAt"`
+	LastSeenAt    string  `json:"lastSeenAt"`
+	DeactivatedAt *string `json:"deactivatedAt"`
+}
+
+func activationFromModel(a *core.Activation) *activationResponse {
+	if a == nil {
+		return nil
+	}
+
+	return &activationResponse{
+		ID:            a.ID.String(),
+		LicenseID:     a.LicenseID.String(),
+		Fingerprint:   a.Fingerprint,
+		Hostname:      a.Hostname,
+		Version:       a.Version,
+		Offline:       a.Offline,
+		Active:        a.IsActive(),
+		Stale:         a.IsStale(time.Now()),
+		FirstSeenAt:   strconv.FormatInt(a.FirstSeenAt.Unix(), 10),
+		LastSeenAt:    strconv.FormatInt(a.LastSeenAt.Unix(), 10),
+		DeactivatedAt: formatUnixPtr(a.DeactivatedAt),
+	}
+}
+
+type activateLicenseRequest struct {
+	Key         string `json:"key" validate:"required"`
+	Fingerprint string `json:"fingerprint" validate:"required,max=255"`
+	Hostname    string `json:"hostname" validate:"max=255"`
+	Version     string `json:"version" validate:"required,max=64"`
+}
+
+type activateLicenseResponse struct {
+	InstanceID  string `json:"instanceId"`
+	LicenseID   string `json:"licenseId"`
+	FirstSeenAt string `json:"firstSeenAt"`
+}
+
+func (s *Server) handleActivateLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req activateLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	l, err := s.db.License().GetByKeyHash(ctx, core.HashLicenseKey(req.Key))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	a, err := s.activateInstance(ctx, l, p, req.Fingerprint, req.Hostname, req.Version, false, now)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, activateLicenseResponse{
+		InstanceID:  a.ID.String(),
+		LicenseID:   l.ID.String(),
+		FirstSeenAt: strconv.FormatInt(a.FirstSeenAt.Unix(), 10),
+	})
+}
+
+// activateInstance records the instance identified by fingerprint against l. Activating
+// the same fingerprint again refreshes the existing record instead of using another slot.
+func (s *Server) activateInstance(ctx context.Context, l *core.License, p *core.Plan, fingerprint, hostname, version string, offline bool, now time.Time) (*core.Activation, error) {
+	var a *core.Activation
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		// Lock the license so that concurrent activations cannot exceed the limit.
+		if _, err := tx.License().GetByIDForUpdate(ctx, l.ID); err != nil {
+			return err
+		}
+
+		existing, err := tx.Activation().GetByLicenseIDAndFingerprint(ctx, l.ID, fingerprint)
+		if err != nil && !errdefs.IsActivationNotFound(err) {
+			return err
+		}
+
+		if existing == nil || !existing.IsActive() {
+			count, err := tx.Activation().CountActiveByLicenseID(ctx, l.ID)
+			if err != nil {
+				return err
+			}
+			if !core.CanActivate(count, p.MaxInstances) {
+				return errdefs.ErrActivationLimitReached(fmt.Errorf("license allows at most %d instances", p.MaxInstances))
+			}
+		}
+
+		if existing != nil {
+			existing.Hostname = hostname
+			existing.Version = version
+			existing.Offline = offline
+			existing.LastSeenAt = now
+			existing.DeactivatedAt = nil
+			a = existing
+			return tx.Activation().Update(ctx, a)
+		}
+
+		a = &core.Activation{
+			ID:          uuid.Must(uuid.NewV4()),
+			LicenseID:   l.ID,
+			Fingerprint: fingerprint,
+			Hostname:    hostname,
+			Version:     version,
+			Offline:     offline,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		}
+		return tx.Activation().Create(ctx, a)
+	}); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+type listLicenseActivationsResponse struct {
+	Activations []*activationResponse `json:"activations"`
+}
+
+func (s *Server) handleListLicenseActivations(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	activations, err := s.db.Activation().ListByLicenseID(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*activationResponse, 0, len(activations))
+	for _, a := range activations {
+		res = append(res, activationFromModel(a))
+	}
+
+	return s.renderJSON(w, http.StatusOK, listLicenseActivationsResponse{
+		Activations: res,
+	})
+}
+
+type deactivateLicenseActivationResponse struct {
+	Activation *activationResponse `json:"activation"`
+}
+
+func (s *Server) handleDeactivateLicenseActivation(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	activationID, err := uuid.FromString(chi.URLParam(r, "activationID"))
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	a, err := s.db.Activation().GetByID(ctx, activationID)
+	if err != nil {
+		return err
+	}
+	if a.LicenseID != l.ID {
+		return errdefs.ErrActivationNotFound(errors.New("activation does not belong to the license"))
+	}
+
+	if a.IsActive() {
+		now := time.Now()
+		a.DeactivatedAt = &now
+
+		if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+			return tx.Activation().Update(ctx, a)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return s.renderJSON(w, http.StatusOK, deactivateLicenseActivationResponse{
+		Activation: activationFromModel(a),
+	})
+}
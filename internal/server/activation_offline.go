@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+const (
+	offlineActivationRequestVersion  = 1
+	offlineActivationResponseVersion = 1
+
+	// maxOfflineActivationRequestSize bounds the uploaded request file.
+	maxOfflineActivationRequestSize = 64 << 10
+)
+
+// offlineActivationRequest is the file an air-gapped instance produces to request
+// activation. It carries the hash of the license key rather than the key itself.
+type offlineActivationRequest struct {
+	Version     int    `json:"version" validate:"required"`
+	KeyHash     string `json:"keyHash" validate:"required,len=64,hexadecimal"`
+	Fingerprint string `json:"fingerprint" validate:"required,max=255"`
+	Hostname    string `json:"hostname" validate:"max=255"`
+	// ProductVersion is the version of Sourcetool running on the instance.
+	ProductVersion string `json:"productVersion" validate:"required,max=64"`
+}
+
+// offlineActivationPayload is the signed body of the response file. The instance
+// checks that Fingerprint matches its own before trusting the embedded license.
+type offlineActivationPayload struct {
+	Version     int                 `json:"version"`
+	InstanceID  string              `json:"instanceId"`
+	Fingerprint string              `json:"fingerprint"`
+	License     *licenseFilePayload `json:"license"`
+}
+
+// handleActivateLicenseOffline activates an instance that cannot reach the portal. The
+// customer uploads the instance's activation request file as the "file" form field and
+// gets back a signed activation response file to import into the instance.
+func (s *Server) handleActivateLicenseOffline(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxOfflineActivationRequestSize)
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+	defer f.Close()
+
+	var req offlineActivationRequest
+	if err := json.NewDecoder(f).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+	if req.Version != offlineActivationRequestVersion {
+		return errdefs.ErrInvalidArgument(fmt.Errorf("unsupported activation request version %d", req.Version))
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	// The request must have been produced with the license's current key.
+	if req.KeyHash != l.KeyHash {
+		return errdefs.ErrInvalidArgument(errors.New("activation request was not created with this license's key"))
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.db.User().GetByID(ctx, l.UserID)
+	if err != nil {
+		return err
+	}
+
+	a, err := s.activateInstance(ctx, l, p, req.Fingerprint, req.Hostname, req.ProductVersion, true, now)
+	if err != nil {
+		return err
+	}
+
+	doc, err := s.signer.Sign(&offlineActivationPayload{
+		Version:     offlineActivationResponseVersion,
+		InstanceID:  a.ID.String(),
+		Fingerprint: a.Fingerprint,
+		License:     licenseFilePayloadFromModel(owner, l, p, now),
+	})
+	if err != nil {
+		return err
+	}
+
+	e := licenseAuditEntry(core.AuditActionLicenseActivatedOffline, l)
+	e.Payload = map[string]any{"instanceId": a.ID.String(), "fingerprint": a.Fingerprint}
+	if err := s.recordAudit(r, s.db.AuditEvent(), e); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="sourcetool-activation.json"`)
+	return s.renderJSON(w, http.StatusOK, doc)
+}
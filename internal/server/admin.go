@@ -0,0 +1,534 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+func uuidFromQuery(r *http.Request, name string) (uuid.UUID, bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return uuid.Nil, false, nil
+	}
+	id, err := uuid.FromString(v)
+	if err != nil {
+		return uuid.Nil, false, errdefs.ErrInvalidArgument(err)
+	}
+	return id, true, nil
+}
+
+type adminListUsersResponse struct {
+	Users      []*userResponse    `json:"users"`
+	Pagination paginationResponse `json:"pagination"`
+}
+
+func (s *Server) handleAdminListUsers(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return err
+	}
+
+	queries := []database.UserQuery{
+		database.UserLimit(limit),
+		database.UserOffset(offset),
+	}
+	if term := strings.TrimSpace(r.URL.Query().Get("q")); term != "" {
+		queries = append(queries, database.UserBySearch(term))
+	}
+
+	users, err := s.db.User().List(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.db.User().Count(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), auditEntry{
+		Action:  core.AuditActionAdminUsersListed,
+		Payload: map[string]any{"query": r.URL.RawQuery},
+	}); err != nil {
+		return err
+	}
+
+	res := make([]*userResponse, 0, len(users))
+	for _, u := range users {
+		res = append(res, s.userFromModel(u, nil, nil))
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminListUsersResponse{
+		Users: res,
+		Pagination: paginationResponse{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
+
+type adminMembershipResponse struct {
+	Organization *organizationResponse `json:"organization"`
+	Role         string                `json:"role"`
+}
+
+type adminGetUserResponse struct {
+	User          *userResponse              `json:"user"`
+	Organizations []*adminMembershipResponse `json:"organizations"`
+	Licenses      []*licenseResponse         `json:"licenses"`
+}
+
+func (s *Server) handleAdminGetUser(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	userID, err := uuid.FromString(chi.URLParam(r, "userID"))
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	u, err := s.db.User().GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	organizations, err := s.db.Organization().ListByUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	memberships := make([]*adminMembershipResponse, 0, len(organizations))
+	for _, o := range organizations {
+		m, err := s.db.Organization().GetMember(ctx, o.ID, u.ID)
+		if err != nil {
+			return err
+		}
+		memberships = append(memberships, &adminMembershipResponse{
+			Organization: organizationFromModel(o),
+			Role:         string(m.Role),
+		})
+	}
+
+	licenses, err := s.db.License().List(ctx, database.LicenseByMemberUserID(u.ID))
+	if err != nil {
+		return err
+	}
+
+	planByID, err := s.listPlansByID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), auditEntry{
+		Action:     core.AuditActionAdminUserViewed,
+		TargetType: core.AuditTargetUser,
+		TargetID:   u.ID.String(),
+	}); err != nil {
+		return err
+	}
+
+	licenseRes := make([]*licenseResponse, 0, len(licenses))
+	for _, l := range licenses {
+		licenseRes = append(licenseRes, licenseSummaryFromModel(l, planByID[l.PlanID]))
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminGetUserResponse{
+		User:          s.userFromModel(u, nil, nil),
+		Organizations: memberships,
+		Licenses:      licenseRes,
+	})
+}
+
+type adminListLicensesResponse struct {
+	Licenses   []*licenseResponse `json:"licenses"`
+	Pagination paginationResponse `json:"pagination"`
+}
+
+func (s *Server) handleAdminListLicenses(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return err
+	}
+
+	q := r.URL.Query()
+	queries := []database.LicenseQuery{
+		database.LicenseLimit(limit),
+		database.LicenseOffset(offset),
+	}
+	if term := strings.TrimSpace(q.Get("q")); term != "" {
+		queries = append(queries, database.LicenseBySearch(term))
+	}
+	if status := q.Get("status"); status != "" {
+		queries = append(queries, database.LicenseByStatus(core.LicenseStatus(status)))
+	}
+	if organizationID, ok, err := uuidFromQuery(r, "organizationId"); err != nil {
+		return err
+	} else if ok {
+		queries = append(queries, database.LicenseByOrganizationID(organizationID))
+	}
+	if userID, ok, err := uuidFromQuery(r, "userId"); err != nil {
+		return err
+	} else if ok {
+		queries = append(queries, database.LicenseByMemberUserID(userID))
+	}
+
+	licenses, err := s.db.License().List(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.db.License().Count(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	planByID, err := s.listPlansByID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), auditEntry{
+		Action:  core.AuditActionAdminLicensesListed,
+		Payload: map[string]any{"query": r.URL.RawQuery},
+	}); err != nil {
+		return err
+	}
+
+	res := make([]*licenseResponse, 0, len(licenses))
+	for _, l := range licenses {
+		res = append(res, licenseSummaryFromModel(l, planByID[l.PlanID]))
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminListLicensesResponse{
+		Licenses: res,
+		Pagination: paginationResponse{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
+
+func (s *Server) adminLicenseFromRequest(r *http.Request) (*core.License, error) {
+	licenseID, err := uuid.FromString(chi.URLParam(r, "licenseID"))
+	if err != nil {
+		return nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	return s.db.License().GetByID(r.Context(), licenseID)
+}
+
+// adminLicenseResponse omits the plaintext key except from handleAdminGetLicense,
+// which records each view in the audit log.
+type adminLicenseResponse struct {
+	License      *licenseResponse      `json:"license"`
+	Organization *organizationResponse `json:"organization"`
+	User         *userResponse         `json:"user"`
+}
+
+func (s *Server) adminLicenseFromModel(r *http.Request, l *core.License) (*adminLicenseResponse, error) {
+	ctx := r.Context()
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.db.Organization().GetByID(ctx, l.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.db.User().GetByID(ctx, l.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adminLicenseResponse{
+		License:      licenseSummaryFromModel(l, p),
+		Organization: organizationFromModel(o),
+		User:         s.userFromModel(u, nil, nil),
+	}, nil
+}
+
+func (s *Server) handleAdminGetLicense(w http.ResponseWriter, r *http.Request) error {
+	l, err := s.adminLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.adminLicenseFromModel(r, l)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.openLicenseKey(l)
+	if err != nil {
+		return err
+	}
+	res.License.Key = string(key)
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), licenseAuditEntry(core.AuditActionAdminLicenseViewed, l)); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleAdminSuspendLicense(w http.ResponseWriter, r *http.Request) error {
+	return s.adminUpdateLicenseStatus(w, r, core.AuditActionAdminLicenseSuspended, (*core.License).Suspend)
+}
+
+func (s *Server) handleAdminReactivateLicense(w http.ResponseWriter, r *http.Request) error {
+	return s.adminUpdateLicenseStatus(w, r, core.AuditActionAdminLicenseReactivated, (*core.License).Reactivate)
+}
+
+func (s *Server) handleAdminRevokeLicense(w http.ResponseWriter, r *http.Request) error {
+	return s.adminUpdateLicenseStatus(w, r, core.AuditActionAdminLicenseRevoked, (*core.License).Revoke)
+}
+
+func (s *Server) adminUpdateLicenseStatus(w http.ResponseWriter, r *http.Request, action core.AuditAction, transition func(*core.License) error) error {
+	ctx := r.Context()
+
+	l, err := s.adminLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := transition(l); err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+
+		if err := tx.License().Update(ctx, l); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), licenseAuditEntry(action, l))
+	}); err != nil {
+		return err
+	}
+
+	res, err := s.adminLicenseFromModel(r, l)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, res)
+}
+
+type adminIssueLicenseRequest struct {
+	OrganizationID string   `json:"organizationId" validate:"required,uuid"`
+	UserID         string   `json:"userId" validate:"required,uuid"`
+	PlanCode       string   `json:"planCode" validate:"required"`
+	Name           string   `json:"name" validate:"required,max=255"`
+	Labels         []string `json:"labels" validate:"max=20,dive,required,max=64"`
+	// ExpiresAt is a unix timestamp. Omit it for a perpetual license.
+	ExpiresAt *int64 `json:"expiresAt"`
+}
+
+// handleAdminIssueLicense issues a license outside the self-service flow, e.g. for a
+// customer who bought an enterprise plan through sales.
+func (s *Server) handleAdminIssueLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminIssueLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	organizationID := uuid.FromStringOrNil(req.OrganizationID)
+	userID := uuid.FromStringOrNil(req.UserID)
+
+	// The requesting user is shown as the licensee, so they must belong to the organization.
+	if _, err := s.db.Organization().GetMember(ctx, organizationID, userID); err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByCode(ctx, req.PlanCode)
+	if err != nil {
+		return err
+	}
+
+	l, err := s.newLicense(organizationID, userID, p, req.Name, req.Labels)
+	if err != nil {
+		return err
+	}
+
+	if req.ExpiresAt != nil {
+		expiresAt := time.Unix(*req.ExpiresAt, 0)
+		if !expiresAt.After(time.Now()) {
+			return errdefs.ErrInvalidArgument(errors.New("expiresAt must be in the future"))
+		}
+		l.ExpiresAt = &expiresAt
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.License().Create(ctx, l); err != nil {
+			return err
+		}
+
+		e := licenseAuditEntry(core.AuditActionAdminLicenseIssued, l)
+		e.Payload = map[string]any{"planCode": p.Code, "userId": l.UserID.String()}
+		return s.recordAudit(r, tx.AuditEvent(), e)
+	}); err != nil {
+		return err
+	}
+
+	// Reload to pick up database defaults such as created_at.
+	l, err = s.db.License().GetByID(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.adminLicenseFromModel(r, l)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, res)
+}
+
+type adminUpgradeLicenseRequest struct {
+	PlanCode string `json:"planCode" validate:"required"`
+	// ExpiresAt is a unix timestamp. Omit it for a perpetual license.
+	ExpiresAt *int64 `json:"expiresAt"`
+}
+
+// handleAdminUpgradeLicense converts a trial into a paid license. The key is unchanged,
+// so instances already running with it keep working.
+func (s *Server) handleAdminUpgradeLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminUpgradeLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	l, err := s.adminLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByCode(ctx, req.PlanCode)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		t := time.Unix(*req.ExpiresAt, 0)
+		expiresAt = &t
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := l.UpgradeTrial(p.ID, expiresAt, time.Now()); err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+
+		if err := tx.License().Update(ctx, l); err != nil {
+			return err
+		}
+
+		e := licenseAuditEntry(core.AuditActionAdminLicenseUpgraded, l)
+		e.Payload = map[string]any{"planCode": p.Code}
+		return s.recordAudit(r, tx.AuditEvent(), e)
+	}); err != nil {
+		return err
+	}
+
+	res, err := s.adminLicenseFromModel(r, l)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, res)
+}
+
+type adminRenewLicenseRequest struct {
+	// ExpiresAt is a unix timestamp and must be in the future.
+	ExpiresAt int64 `json:"expiresAt" validate:"required"`
+}
+
+// handleAdminRenewLicense extends a paid license, reactivating it if it has already
+// lapsed. The key is unchanged, so instances already running with it keep working.
+func (s *Server) handleAdminRenewLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminRenewLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	l, err := s.adminLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := l.Renew(time.Unix(req.ExpiresAt, 0), time.Now()); err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+
+		if err := tx.License().Update(ctx, l); err != nil {
+			return err
+		}
+
+		e := licenseAuditEntry(core.AuditActionAdminLicenseRenewed, l)
+		e.Payload = map[string]any{"expiresAt": req.ExpiresAt}
+		return s.recordAudit(r, tx.AuditEvent(), e)
+	}); err != nil {
+		return err
+	}
+
+	res, err := s.adminLicenseFromModel(r, l)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/semver"
+)
+
+func advisoryAuditEntry(action core.AuditAction, a *core.SecurityAdvisory) auditEntry {
+	return auditEntry{
+		Action:     action,
+		TargetType: core.AuditTargetAdvisory,
+		TargetID:   a.ID.String(),
+		Payload: map[string]any{
+			"product":    a.Product,
+			"identifier": a.Identifier,
+		},
+	}
+}
+
+func (s *Server) adminAdvisoryFromRequest(r *http.Request) (*core.SecurityAdvisory, error) {
+	advisoryID, err := uuid.FromString(chi.URLParam(r, "advisoryID"))
+	if err != nil {
+		return nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	return s.db.Advisory().GetByID(r.Context(), advisoryID)
+}
+
+type adminListAdvisoriesResponse struct {
+	Advisories []*advisoryResponse `json:"advisories"`
+	Pagination paginationResponse  `json:"pagination"`
+}
+
+func (s *Server) handleAdminListAdvisories(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return err
+	}
+
+	queries := []database.AdvisoryQuery{
+		database.AdvisoryLimit(limit),
+		database.AdvisoryOffset(offset),
+	}
+	if product := r.URL.Query().Get("product"); product != "" {
+		queries = append(queries, database.AdvisoryByProduct(product))
+	}
+
+	advisories, err := s.db.Advisory().List(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.db.Advisory().Count(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*advisoryResponse, 0, len(advisories))
+	for _, a := range advisories {
+		res = append(res, advisoryFromModel(a))
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminListAdvisoriesResponse{
+		Advisories: res,
+		Pagination: paginationResponse{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
+
+type adminAdvisoryRequest struct {
+	Product     string `json:"product" validate:"required,max=64"`
+	Identifier  string `json:"identifier" validate:"required,max=64"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description"`
+	Severity    string `json:"severity" validate:"required,oneof=low medium high critical"`
+	URL         string `json:"url" validate:"omitempty,url,max=1024"`
+	// IntroducedVersion and FixedVersion bound the affected versions. Omit either for
+	// an open-ended range.
+	IntroducedVersion string `json:"introducedVersion" validate:"max=64"`
+	FixedVersion      string `json:"fixedVersion" validate:"max=64"`
+	// PublishedAt is a unix timestamp. It defaults to now.
+	PublishedAt *int64 `json:"publishedAt"`
+}
+
+// validate checks the version bounds, which must be semantic versions for the range
+// to be matched against the versions instances report.
+func (req adminAdvisoryRequest) validate() error {
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	for _, v := range []string{req.IntroducedVersion, req.FixedVersion} {
+		if v == "" {
+			continue
+		}
+		if _, err := semver.Parse(v); err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+	}
+
+	return nil
+}
+
+func (req adminAdvisoryRequest) apply(a *core.SecurityAdvisory) {
+	a.Product = req.Product
+	a.Identifier = req.Identifier
+	a.Title = req.Title
+	a.Description = req.Description
+	a.Severity = req.Severity
+	a.URL = req.URL
+	a.IntroducedVersion = req.IntroducedVersion
+	a.FixedVersion = req.FixedVersion
+	if req.PublishedAt != nil {
+		a.PublishedAt = time.Unix(*req.PublishedAt, 0)
+	}
+}
+
+type adminAdvisoryResponse struct {
+	Advisory *advisoryResponse `json:"advisory"`
+}
+
+func (s *Server) handleAdminCreateAdvisory(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminAdvisoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	a := &core.SecurityAdvisory{
+		ID:          uuid.Must(uuid.NewV4()),
+		PublishedAt: time.Now(),
+	}
+	req.apply(a)
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Advisory().Create(ctx, a); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), advisoryAuditEntry(core.AuditActionAdminAdvisoryCreated, a))
+	}); err != nil {
+		return err
+	}
+
+	// Reload to pick up database defaults such as created_at.
+	a, err := s.db.Advisory().GetByID(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, adminAdvisoryResponse{
+		Advisory: advisoryFromModel(a),
+	})
+}
+
+func (s *Server) handleAdminUpdateAdvisory(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminAdvisoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	a, err := s.adminAdvisoryFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	req.apply(a)
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Advisory().Update(ctx, a); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), advisoryAuditEntry(core.AuditActionAdminAdvisoryUpdated, a))
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminAdvisoryResponse{
+		Advisory: advisoryFromModel(a),
+	})
+}
+
+func (s *Server) handleAdminDeleteAdvisory(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	a, err := s.adminAdvisoryFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Advisory().Delete(ctx, a.ID); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), advisoryAuditEntry(core.AuditActionAdminAdvisoryDeleted, a))
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, statusResponse{
+		Code:    http.StatusOK,
+		Message: "Successfully deleted advisory",
+	})
+}
+
+const (
+	defaultVersionCountDays = 30
+	maxVersionCountDays     = 365
+)
+
+type versionCountResponse struct {
+	Version  string `json:"version"`
+	Licenses int64  `json:"licenses"`
+}
+
+type adminListReleaseVersionsResponse struct {
+	Product  string                  `json:"product"`
+	Since    string                  `json:"since"`
+	Versions []*versionCountResponse `json:"versions"`
+}
+
+// handleAdminListReleaseVersions reports which versions of a product are running in
+// the field, based on the update checks of the last days (30 by default).
+func (s *Server) handleAdminListReleaseVersions(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := r.URL.Query()
+	product := q.Get("product")
+	if product == "" {
+		product = core.ProductSourcetool
+	}
+
+	days := defaultVersionCountDays
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxVersionCountDays {
+			return errdefs.ErrInvalidArgument(fmt.Errorf("days must be between 1 and %d", maxVersionCountDays))
+		}
+		days = n
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	counts, err := s.db.Release().CountVersions(ctx, product, since)
+	if err != nil {
+		return err
+	}
+
+	versions := make([]*versionCountResponse, 0, len(counts))
+	for _, c := range counts {
+		versions = append(versions, &versionCountResponse{
+			Version:  c.Version,
+			Licenses: c.Licenses,
+		})
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminListReleaseVersionsResponse{
+		Product:  product,
+		Since:    strconv.FormatInt(since.Unix(), 10),
+		Versions: versions,
+	})
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+func releaseAuditEntry(action core.AuditAction, rel *core.Release, payload map[string]any) auditEntry {
+	return auditEntry{
+		Action:     action,
+		TargetType: core.AuditTargetRelease,
+		TargetID:   rel.ID.String(),
+		Payload:    payload,
+	}
+}
+
+func (s *Server) adminReleaseFromRequest(r *http.Request) (*core.Release, error) {
+	releaseID, err := uuid.FromString(chi.URLParam(r, "releaseID"))
+	if err != nil {
+		return nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	return s.db.Release().GetByID(r.Context(), releaseID)
+}
+
+type adminListReleasesResponse struct {
+	Releases   []*releaseResponse `json:"releases"`
+	Pagination paginationResponse `json:"pagination"`
+}
+
+// handleAdminListReleases lists every release regardless of entitlement.
+func (s *Server) handleAdminListReleases(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return err
+	}
+
+	q := r.URL.Query()
+	queries := []database.ReleaseQuery{
+		database.ReleaseLimit(limit),
+		database.ReleaseOffset(offset),
+	}
+	if product := q.Get("product"); product != "" {
+		queries = append(queries, database.ReleaseByProduct(product))
+	}
+	if channel := q.Get("channel"); channel != "" {
+		queries = append(queries, database.ReleaseByChannel(channel))
+	}
+
+	releases, err := s.db.Release().List(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.db.Release().Count(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.releasesFromModels(r, releases)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminListReleasesResponse{
+		Releases: res,
+		Pagination: paginationResponse{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
+
+type adminReleaseRequest struct {
+	Product string `json:"product" validate:"required,max=64"`
+	Version string `json:"version" validate:"required,max=64"`
+	Channel string `json:"channel" validate:"required,oneof=stable beta"`
+	Notes   string `json:"notes"`
+	// Editions restricts the release to plans of these editions. Omit it for every edition.
+	Editions []string `json:"editions" validate:"max=20,dive,required,max=64"`
+	// ReleasedAt is a unix timestamp. It defaults to now.
+	ReleasedAt *int64 `json:"releasedAt"`
+}
+
+func (req adminReleaseRequest) apply(rel *core.Release) {
+	editions := req.Editions
+	if editions == nil {
+		editions = []string{}
+	}
+
+	rel.Product = req.Product
+	rel.Version = req.Version
+	rel.Channel = req.Channel
+	rel.Notes = req.Notes
+	rel.Editions = editions
+	if req.ReleasedAt != nil {
+		rel.ReleasedAt = time.Unix(*req.ReleasedAt, 0)
+	}
+}
+
+type adminReleaseResponse struct {
+	Release *releaseResponse `json:"release"`
+}
+
+func (s *Server) handleAdminCreateRelease(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	rel := &core.Release{
+		ID:         uuid.Must(uuid.NewV4()),
+		ReleasedAt: time.Now(),
+	}
+	req.apply(rel)
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Release().Create(ctx, rel); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), releaseAuditEntry(core.AuditActionAdminReleaseCreated, rel, map[string]any{
+			"product": rel.Product,
+			"version": rel.Version,
+		}))
+	}); err != nil {
+		return err
+	}
+
+	// Reload to pick up database defaults such as created_at.
+	rel, err := s.db.Release().GetByID(ctx, rel.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, adminReleaseResponse{
+		Release: releaseFromModel(rel, nil),
+	})
+}
+
+func (s *Server) handleAdminUpdateRelease(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	rel, err := s.adminReleaseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	req.apply(rel)
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Release().Update(ctx, rel); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), releaseAuditEntry(core.AuditActionAdminReleaseUpdated, rel, nil))
+	}); err != nil {
+		return err
+	}
+
+	res, err := s.releasesFromModels(r, []*core.Release{rel})
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, adminReleaseResponse{
+		Release: res[0],
+	})
+}
+
+// handleAdminDeleteRelease removes a release from the catalog. Its files are left in
+// the artifacts directory.
+func (s *Server) handleAdminDeleteRelease(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	rel, err := s.adminReleaseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Release().Delete(ctx, rel.ID); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), releaseAuditEntry(core.AuditActionAdminReleaseDeleted, rel, map[string]any{
+			"product": rel.Product,
+			"version": rel.Version,
+		}))
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, statusResponse{
+		Code:    http.StatusOK,
+		Message: "Successfully deleted release",
+	})
+}
+
+type adminCreateReleaseArtifactRequest struct {
+	// Path locates the file relative to ARTIFACTS_DIR.
+	Path string `json:"path" validate:"required,max=1024"`
+	// Name is the file name customers download it as. It defaults to the base name of Path.
+	Name string `json:"name" validate:"max=255"`
+}
+
+type adminReleaseArtifactResponse struct {
+	Artifact *releaseArtifactResponse `json:"artifact"`
+}
+
+// handleAdminCreateReleaseArtifact registers a file already placed in the artifacts
+// directory, recording its size and SHA-256 checksum.
+func (s *Server) handleAdminCreateReleaseArtifact(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req adminCreateReleaseArtifactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	artifactPath := filepath.Clean(req.Path)
+	if !filepath.IsLocal(artifactPath) {
+		return errdefs.ErrInvalidArgument(errors.New("path must be relative to the artifacts directory"))
+	}
+
+	rel, err := s.adminReleaseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	name := req.Name
+	if name == "" {
+		name = filepath.Base(artifactPath)
+	}
+
+	a := &core.ReleaseArtifact{
+		ID:        uuid.Must(uuid.NewV4()),
+		ReleaseID: rel.ID,
+		Name:      name,
+		Path:      artifactPath,
+	}
+	a.Size, a.SHA256, err = hashReleaseArtifact(releaseArtifactPath(a))
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Release().CreateArtifact(ctx, a); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), releaseAuditEntry(core.AuditActionAdminArtifactCreated, rel, map[string]any{
+			"artifactId": a.ID.String(),
+			"name":       a.Name,
+			"sha256":     a.SHA256,
+		}))
+	}); err != nil {
+		return err
+	}
+
+	a, err = s.db.Release().GetArtifact(ctx, rel.ID, a.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, adminReleaseArtifactResponse{
+		Artifact: releaseArtifactFromModel(a),
+	})
+}
+
+func hashReleaseArtifact(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, "", errdefs.ErrInvalidArgument(errors.New("artifact file does not exist"))
+		}
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s *Server) handleAdminDeleteReleaseArtifact(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	rel, a, err := s.releaseArtifactFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Release().DeleteArtifact(ctx, rel.ID, a.ID); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), releaseAuditEntry(core.AuditActionAdminArtifactDeleted, rel, map[string]any{
+			"artifactId": a.ID.String(),
+			"name":       a.Name,
+		}))
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, statusResponse{
+		Code:    http.StatusOK,
+		Message: "Successfully deleted artifact",
+	})
+}
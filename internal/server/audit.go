@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/zap"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/logger"
+)
+
+// auditEntry describes an event to record. The actor defaults to the signed-in user.
+type auditEntry struct {
+	Action         core.AuditAction
+	Actor          *core.User
+	OrganizationID *uuid.UUID
+	TargetType     string
+	TargetID       string
+	Payload        map[string]any
+}
+
+func userAuditEntry(action core.AuditAction, u *core.User, payload map[string]any) auditEntry {
+	return auditEntry{
+		Action:     action,
+		Actor:      u,
+		TargetType: core.AuditTargetUser,
+		TargetID:   u.ID.String(),
+		Payload:    payload,
+	}
+}
+
+func licenseAuditEntry(action core.AuditAction, l *core.License) auditEntry {
+	return auditEntry{
+		Action:         action,
+		OrganizationID: &l.OrganizationID,
+		TargetType:     core.AuditTargetLicense,
+		TargetID:       l.ID.String(),
+	}
+}
+
+// recordAudit appends e to the audit log along with the client's address, user agent and
+// request ID. Pass the transaction's store to record the event atomically with a change.
+func (s *Server) recordAudit(r *http.Request, store database.AuditEventStore, e auditEntry) error {
+	ctx := r.Context()
+
+	payload := []byte(`{}`)
+	if e.Payload != nil {
+		var err error
+		payload, err = json.Marshal(e.Payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	actor := e.Actor
+	if actor == nil {
+		actor = internal.ContextUser(ctx)
+	}
+	var actorUserID *uuid.UUID
+	if actor != nil {
+		actorUserID = &actor.ID
+	}
+
+	return store.Create(ctx, &core.AuditEvent{
+		ID:             uuid.Must(uuid.NewV4()),
+		Action:         e.Action,
+		ActorUserID:    actorUserID,
+		OrganizationID: e.OrganizationID,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		IPAddress:      clientIP(r),
+		UserAgent:      r.UserAgent(),
+		RequestID:      middleware.GetReqID(ctx),
+		Payload:        payload,
+	})
+}
+
+// trustedProxies parses TRUSTED_PROXIES once. Invalid entries are logged and skipped.
+var trustedProxies = sync.OnceValue(func() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, v := range strings.Split(config.Config.Proxy.Trusted, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				logger.Logger.Warn("invalid trusted proxy", zap.String("value", v), zap.Error(err))
+				continue
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			logger.Logger.Warn("invalid trusted proxy", zap.String("value", v), zap.Error(err))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+})
+
+func isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range trustedProxies() {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the client that made the request. When the request
+// comes through a trusted proxy, it is the last X-Forwarded-For entry not added by a
+// trusted proxy, since anything before it can be set by the client.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	remote, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(remote) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			// A malformed entry cannot be trusted, nor anything the client put before it.
+			return host
+		}
+		if !isTrustedProxy(addr) {
+			return addr.Unmap().String()
+		}
+		host = addr.Unmap().String()
+	}
+	return host
+}
+
+type auditEventResponse struct {
+	ID             string          `json:"id"`
+	Action         string          `json:"action"`
+	OrganizationID *string         `json:"organizationId"`
+	TargetType     string          `json:"targetType"`
+	TargetID       string          `json:"targetId"`
+	IPAddress      string          `json:"ipAddress"`
+	UserAgent      string          `json:"userAgent"`
+	RequestID      string          `json:"requestId"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      string          `json:"createdAt"`
+}
+
+func auditEventFromModel(e *core.AuditEvent) *auditEventResponse {
+	if e == nil {
+		return nil
+	}
+
+	var organizationID *string
+	if e.OrganizationID != nil {
+		v := e.OrganizationID.String()
+		organizationID = &v
+	}
+
+	return &auditEventResponse{
+		ID:             e.ID.String(),
+		Action:         string(e.Action),
+		OrganizationID: organizationID,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		IPAddress:      e.IPAddress,
+		UserAgent:      e.UserAgent,
+		RequestID:      e.RequestID,
+		Payload:        e.Payload,
+		CreatedAt:      strconv.FormatInt(e.CreatedAt.Unix(), 10),
+	}
+}
+
+type listMyAuditEventsResponse struct {
+	Events     []*auditEventResponse `json:"events"`
+	Pagination paginationResponse    `json:"pagination"`
+}
+
+// handleListMyAuditEvents returns the events performed by the signed-in user, newest first.
+func (s *Server) handleListMyAuditEvents(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return err
+	}
+
+	ctxUser := internal.ContextUser(ctx)
+	queries := []database.AuditEventQuery{
+		database.AuditEventByActorUserID(ctxUser.ID),
+		database.AuditEventLimit(limit),
+		database.AuditEventOffset(offset),
+	}
+
+	events, err := s.db.AuditEvent().List(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.db.AuditEvent().Count(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*auditEventResponse, 0, len(events))
+	for _, e := range events {
+		res = append(res, auditEventFromModel(e))
+	}
+
+	return s.renderJSON(w, http.StatusOK, listMyAuditEventsResponse{
+		Events: res,
+		Pagination: paginationResponse{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
@@ -135,6 +135,12 @@ func (s *Server) handleAuthenticateWithGoogle(w http.ResponseWriter, r *http.Req
 			return err
 		}
 
+		if err := s.recordAudit(r, tx.AuditEvent(), userAuditEntry(core.AuditActionUserLoggedIn, u, map[string]any{
+			"method": "google",
+		})); err != nil {
+			return err
+		}
+
 		return nil
 	}); err != nil {
 		return err
@@ -154,7 +160,8 @@ func (s *Server) handleAuthenticateWithGoogle(w http.ResponseWriter, r *http.Req
 }
 
 type registerWithGoogleRequest struct {
-	Token string `json:"token" validate:"required"`
+	Token           string `json:"token" validate:"required"`
+	InvitationToken string `json:"invitationToken"`
 }
 
 type registerWithGoogleResponse struct {
@@ -203,22 +210,9 @@ func (s *Server) handleRegisterWithGoogle(w http.ResponseWriter, r *http.Request
 		GoogleID:         claims.GoogleID,
 	}
 
-	plainLicenseKey, hashedLicenseKey, err := core.GenerateLicenseKey()
-	if err != nil {
-		return errdefs.ErrInternal(fmt.Errorf("failed to generate license key: %w", err))
-	}
-
-	ciphertext, nonce, err := s.encryptor.Encrypt([]byte(plainLicenseKey))
+	su, err := s.newSignUp(ctx, u, req.InvitationToken)
 	if err != nil {
-		return errdefs.ErrInternal(fmt.Errorf("failed to encrypt license key: %w", err))
-	}
-
-	l := &core.License{
-		ID:            uuid.Must(uuid.NewV4()),
-		UserID:        u.ID,
-		KeyHash:       hashedLicenseKey,
-		KeyCiphertext: ciphertext,
-		KeyNonce:      nonce,
+		return err
 	}
 
 	now := time.Now()
@@ -230,7 +224,15 @@ func (s *Server) handleRegisterWithGoogle(w http.ResponseWriter, r *http.Request
 			return err
 		}
 
-		if err := tx.License().Create(ctx, l); err != nil {
+		if err := su.create(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.recordAudit(r, tx.AuditEvent(), userAuditEntry(core.AuditActionUserRegistered, u, map[string]any{
+			"method":         "google",
+			"organizationId": su.member.OrganizationID.String(),
+			"invited":        su.organization == nil,
+		})); err != nil {
 			return err
 		}
 
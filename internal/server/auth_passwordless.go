@@ -160,6 +160,13 @@ func (s *Server) handleAuthenticateWithMagicLink(w http.ResponseWriter, r *http.
 		if err := tx.User().Update(ctx, u); err != nil {
 			return err
 		}
+
+		if err := s.recordAudit(r, tx.AuditEvent(), userAuditEntry(core.AuditActionUserLoggedIn, u, map[string]any{
+			"method": "magic_link",
+		})); err != nil {
+			return err
+		}
+
 		return nil
 	}); err != nil {
 		return err
@@ -179,9 +186,10 @@ func (s *Server) handleAuthenticateWithMagicLink(w http.ResponseWriter, r *http.
 }
 
 type registerWithMagicLinkRequest struct {
-	Token     string `json:"token" validate:"required"`
-	FirstName string `json:"firstName" validate:"required"`
-	LastName  string `json:"lastName" validate:"required"`
+	Token           string `json:"token" validate:"required"`
+	FirstName       string `json:"firstName" validate:"required"`
+	LastName        string `json:"lastName" validate:"required"`
+	InvitationToken string `json:"invitationToken"`
 }
 
 type registerWithMagicLinkResponse struct {
@@ -220,24 +228,11 @@ func (s *Server) handleRegisterWithMagicLink(w http.ResponseWriter, r *http.Requ
 		RefreshTokenHash: hashedRefreshToken,
 	}
 
-	plainLicenseKey, hashedLicenseKey, err := core.GenerateLicenseKey()
-	if err != nil {
-		return err
-	}
-
-	ciphertext, nonce, err := s.encryptor.Encrypt([]byte(plainLicenseKey))
+	su, err := s.newSignUp(ctx, u, req.InvitationToken)
 	if err != nil {
 		return err
 	}
 
-	l := &core.License{
-		ID:            uuid.Must(uuid.NewV4()),
-		UserID:        u.ID,
-		KeyHash:       hashedLicenseKey,
-		KeyCiphertext: ciphertext,
-		KeyNonce:      nonce,
-	}
-
 	xsrfToken := uuid.Must(uuid.NewV4()).String()
 	now := time.Now()
 	expiresAt := now.Add(core.TokenExpiration())
@@ -249,7 +244,15 @@ func (s *Server) handleRegisterWithMagicLink(w http.ResponseWriter, r *http.Requ
 			return err
 		}
 
-		if err := tx.License().Create(ctx, l); err != nil {
+		if err := su.create(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.recordAudit(r, tx.AuditEvent(), userAuditEntry(core.AuditActionUserRegistered, u, map[string]any{
+			"method":         "magic_link",
+			"organizationId": su.member.OrganizationID.String(),
+			"invited":        su.organization == nil,
+		})); err != nil {
 			return err
 		}
 
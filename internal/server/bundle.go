@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/trysourcetool/onprem-portal/internal/bundle"
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+// bundleRoot is the directory every file in an installation bundle is placed under.
+const bundleRoot = "sourcetool"
+
+func (s *Server) handleGetLicenseBundle(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = bundle.FormatTarGz
+	}
+	var contentType string
+	switch format {
+	case bundle.FormatTarGz:
+		contentType = "application/gzip"
+	case bundle.FormatZip:
+		contentType = "application/zip"
+	default:
+		return errdefs.ErrInvalidArgument(fmt.Errorf("unsupported format %q", format))
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	o, err := s.db.Organization().GetByID(ctx, l.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	plainLicenseKey, err := s.openLicenseKey(l)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.bundleVersion(r, p.Entitlements())
+	if err != nil {
+		return err
+	}
+
+	d := &bundle.Data{
+		OrganizationName: o.Name,
+		LicenseKey:       string(plainLicenseKey),
+		Image:            config.Config.Bundle.Image,
+		Version:          version,
+		ReplicaCount:     bundleReplicaCount(p.Entitlements()),
+	}
+	if err := d.GenerateSecrets(); err != nil {
+		return err
+	}
+
+	// Render the whole archive before writing the response so a failure still
+	// surfaces as an error response rather than a truncated download.
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, format, bundleRoot, d, now); err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), licenseAuditEntry(core.AuditActionLicenseBundleDownloaded, l)); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, bundleRoot, format))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// bundleVersion returns the latest stable release the license is entitled to, or
+// "latest" when none has been published yet.
+func (s *Server) bundleVersion(r *http.Request, e core.Entitlements) (string, error) {
+	releases, err := s.db.Release().List(r.Context(),
+		database.ReleaseByProduct(core.ProductSourcetool),
+		database.ReleaseByChannel(core.ReleaseChannelStable),
+		database.ReleaseByEdition(e.Edition),
+		database.ReleaseLimit(1),
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(releases) == 0 {
+		return "latest", nil
+	}
+	return releases[0].Version, nil
+}
+
+// bundleReplicaCount recommends running two replicas when the plan allows a
+// highly available deployment.
+func bundleReplicaCount(e core.Entitlements) int {
+	if e.HasFeature(core.FeatureHighAvailability) && (e.MaxInstances == 0 || e.MaxInstances >= 2) {
+		return 2
+	}
+	return 1
+}
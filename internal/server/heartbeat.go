@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+type instanceHeartbeatRequest struct {
+	Version       string `json:"version" validate:"required,max=64"`
+	ActiveUsers   int    `json:"activeUsers" validate:"min=0"`
+	UptimeSeconds int64  `json:"uptimeSeconds" validate:"min=0"`
+}
+
+type instanceHeartbeatResponse struct {
+	ReceivedAt string `json:"receivedAt"`
+}
+
+func (s *Server) handleInstanceHeartbeat(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	instanceID, err := uuid.FromString(chi.URLParam(r, "instanceID"))
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	var req instanceHeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	ctxLicense := internal.ContextLicense(ctx)
+	a, err := s.db.Activation().GetByID(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	if a.LicenseID != ctxLicense.ID {
+		return errdefs.ErrActivationNotFound(errors.New("instance does not belong to the license"))
+	}
+	if !a.IsActive() {
+		return errdefs.ErrPermissionDenied(errors.New("instance has been deactivated"))
+	}
+
+	now := time.Now()
+	a.Version = req.Version
+	a.LastSeenAt = now
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Heartbeat().Create(ctx, &core.Heartbeat{
+			ID:            uuid.Must(uuid.NewV4()),
+			ActivationID:  a.ID,
+			BucketStart:   core.HeartbeatBucket(now),
+			Version:       req.Version,
+			ActiveUsers:   req.ActiveUsers,
+			UptimeSeconds: req.UptimeSeconds,
+			ReceivedAt:    now,
+		}); err != nil {
+			return err
+		}
+
+		return tx.Activation().Update(ctx, a)
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, instanceHeartbeatResponse{
+		ReceivedAt: strconv.FormatInt(now.Unix(), 10),
+	})
+}
+
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 365
+)
+
+type dailyUsageResponse struct {
+	Day              string  `json:"day"`
+	HeartbeatCount   int     `json:"heartbeatCount"`
+	MaxActiveUsers   int     `json:"maxActiveUsers"`
+	AvgActiveUsers   float64 `json:"avgActiveUsers"`
+	MaxUptimeSeconds int64   `json:"maxUptimeSeconds"`
+}
+
+type getLicenseActivationUsageResponse struct {
+	Activation *activationResponse   `json:"activation"`
+	Usage      []*dailyUsageResponse `json:"usage"`
+}
+
+func (s *Server) handleGetLicenseActivationUsage(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	activationID, err := uuid.FromString(chi.URLParam(r, "activationID"))
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	days := defaultUsageDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days <= 0 || days > maxUsageDays {
+			return errdefs.ErrInvalidArgument(errors.New("days must be between 1 and 365"))
+		}
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	a, err := s.db.Activation().GetByID(ctx, activationID)
+	if err != nil {
+		return err
+	}
+	if a.LicenseID != l.ID {
+		return errdefs.ErrActivationNotFound(errors.New("activation does not belong to the license"))
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	usage, err := s.db.Heartbeat().ListDailyUsage(ctx, a.ID, since)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*dailyUsageResponse, 0, len(usage))
+	for _, u := range usage {
+		res = append(res, &dailyUsageResponse{
+			Day:              u.Day.Format(time.DateOnly),
+			HeartbeatCount:   u.HeartbeatCount,
+			MaxActiveUsers:   u.MaxActiveUsers,
+			AvgActiveUsers:   u.AvgActiveUsers,
+			MaxUptimeSeconds: u.MaxUptimeSeconds,
+		})
+	}
+
+	return s.renderJSON(w, http.StatusOK, getLicenseActivationUsageResponse{
+		Activation: activationFromModel(a),
+		Usage:      res,
+	})
+}
@@ -1,26 +1,611 @@
 package server
 
-import "github.com/trysourcetool/onprem-portal/internal/core"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/mail"
+	"github.com/trysourcetool/onprem-portal/internal/sign"
+)
 
 type licenseResponse struct {
-	ID     string `json:"id"`
-	UserID string `json:"user_id"`
-	Key    string `json:"key"`
+	ID             string   `json:"id"`
+	OrganizationID string   `json:"organizationId"`
+	UserID         string   `json:"user_id"`
+	Name           string   `json:"name"`
+	Labels         []string `json:"labels"`
+	Key            string   `json:"key,omitempty"`
+	Status         string   `json:"status"`
+	IsTrial        bool     `json:"isTrial"`
+	ExpiresAt      *string  `json:"expiresAt"`
+	ExpiresInDays  *int     `json:"expiresInDays"`
+	RenewedAt      *string  `json:"renewedAt"`
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt"`
+
+	Plan         *planResponse                `json:"plan,omitempty"`
+	Entitlements *licenseEntitlementsResponse `json:"entitlements,omitempty"`
+}
+
+func (s *Server) licenseFromModel(l *core.License, p *core.Plan) *licenseResponse {
+	if l == nil {
+		return nil
+	}
+
+	key, err := s.openLicenseKey(l)
+	if err != nil {
+		return nil
+	}
+
+	res := licenseSummaryFromModel(l, p)
+	res.Key = string(key)
+	return res
 }
 
-func (s *Server) licenseFromModel(l *core.License) *licenseResponse {
+// licenseSummaryFromModel renders a license without its plaintext key, for views
+// that list many licenses at once.
+func licenseSummaryFromModel(l *core.License, p *core.Plan) *licenseResponse {
 	if l == nil {
 		return nil
 	}
 
-	key, err := s.encryptor.Decrypt(l.KeyCiphertext, l.KeyNonce)
+	labels := []string(l.Labels)
+	if labels == nil {
+		labels = []string{}
+	}
+
+	now := time.Now()
+	var expiresInDays *int
+	if days, ok := l.DaysUntilExpiry(now); ok {
+		expiresInDays = &days
+	}
+
+	res := &licenseResponse{
+		ID:             l.ID.String(),
+		OrganizationID: l.OrganizationID.String(),
+		UserID:         l.UserID.String(),
+		Name:           l.Name,
+		Labels:         labels,
+		Status:         string(l.EffectiveStatus(now)),
+		IsTrial:        l.IsTrial,
+		ExpiresAt:      formatUnixPtr(l.ExpiresAt),
+		ExpiresInDays:  expiresInDays,
+		RenewedAt:      formatUnixPtr(l.RenewedAt),
+		CreatedAt:      strconv.FormatInt(l.CreatedAt.Unix(), 10),
+		UpdatedAt:      strconv.FormatInt(l.UpdatedAt.Unix(), 10),
+	}
+	if p != nil {
+		entitlements := licenseEntitlementsFromModel(p.Entitlements())
+		res.Plan = planFromModel(p)
+		res.Entitlements = &entitlements
+	}
+
+	return res
+}
+
+func formatUnixPtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	v := strconv.FormatInt(t.Unix(), 10)
+	return &v
+}
+
+// newLicense generates a fresh key for a new active license on plan p, owned by
+// the organization and requested by userID.
+func (s *Server) newLicense(organizationID, userID uuid.UUID, p *core.Plan, name string, labels []string) (*core.License, error) {
+	plainLicenseKey, hashedLicenseKey, err := core.GenerateLicenseKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if labels == nil {
+		labels = []string{}
+	}
+
+	l := &core.License{
+		ID:             uuid.Must(uuid.NewV4()),
+		OrganizationID: organizationID,
+		UserID:         userID,
+		PlanID:         p.ID,
+		Name:           name,
+		Labels:         labels,
+		KeyHash:        hashedLicenseKey,
+		Status:         core.LicenseStatusActive,
+	}
+	if err := s.sealLicenseKey(l, []byte(plainLicenseKey)); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// sealLicenseKey encrypts plainKey with the primary key, bound to the license's IDs.
+func (s *Server) sealLicenseKey(l *core.License, plainKey []byte) error {
+	keyVersion, ciphertext, nonce, err := s.encryptor.EncryptWithAAD(plainKey, l.KeyAAD())
+	if err != nil {
+		return err
+	}
+
+	l.KeyCiphertext = ciphertext
+	l.KeyNonce = nonce
+	l.KeyVersion = keyVersion
+	l.KeyAADBound = true
+	return nil
+}
+
+func (s *Server) openLicenseKey(l *core.License) ([]byte, error) {
+	if !l.KeyAADBound {
+		return s.encryptor.Decrypt(l.KeyVersion, l.KeyCiphertext, l.KeyNonce)
+	}
+	return s.encryptor.DecryptWithAAD(l.KeyVersion, l.KeyCiphertext, l.KeyNonce, l.KeyAAD())
+}
+
+// getPrimaryLicense returns the oldest license across the user's organizations,
+// which is the one the /users/me/license routes operate on.
+func (s *Server) getPrimaryLicense(ctx context.Context, u *core.User) (*core.License, error) {
+	licenses, err := s.db.License().List(ctx, database.LicenseByMemberUserID(u.ID))
+	if err != nil {
+		return nil, err
+	}
+	if len(licenses) == 0 {
+		return nil, errdefs.ErrLicenseNotFound(errors.New("user has no license"))
+	}
+	return licenses[0], nil
+}
+
+// licenseFromRequest resolves the license a request operates on: the one named by
+// the licenseID URL parameter, or the primary license for /users/me/license routes.
+func (s *Server) licenseFromRequest(r *http.Request) (*core.License, error) {
+	ctx := r.Context()
+	ctxUser := internal.ContextUser(ctx)
+
+	param := chi.URLParam(r, "licenseID")
+	if param == "" {
+		return s.getPrimaryLicense(ctx, ctxUser)
+	}
+
+	licenseID, err := uuid.FromString(param)
+	if err != nil {
+		return nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	l, err := s.db.License().GetByID(ctx, licenseID)
 	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Organization().GetMember(ctx, l.OrganizationID, ctxUser.ID); err != nil {
+		if errdefs.IsOrganizationMemberNotFound(err) {
+			return nil, errdefs.ErrLicenseNotFound(errors.New("license does not belong to the user"))
+		}
+		return nil, err
+	}
+
+	return l, nil
+}
+
+type licenseeResponse struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func licenseeFromModel(u *core.User) licenseeResponse {
+	return licenseeResponse{
+		Name:  u.FullName(),
+		Email: u.Email,
+	}
+}
+
+// licenseLimitsResponse holds the usage limits granted by a license. Zero means unlimited.
+type licenseLimitsResponse struct {
+	Seats     int `json:"seats"`
+	Instances int `json:"instances"`
+}
+
+type licenseEntitlementsResponse struct {
+	Edition      string                `json:"edition"`
+	Limits       licenseLimitsResponse `json:"limits"`
+	Features     []string              `json:"features"`
+	SupportLevel string                `json:"supportLevel"`
+}
+
+func licenseEntitlementsFromModel(e core.Entitlements) licenseEntitlementsResponse {
+	features := e.Features
+	if features == nil {
+		features = []string{}
+	}
+	return licenseEntitlementsResponse{
+		Edition: e.Edition,
+		Limits: licenseLimitsResponse{
+			Seats:     e.SeatLimit,
+			Instances: e.MaxInstances,
+		},
+		Features:     features,
+		SupportLevel: e.SupportLevel,
+	}
+}
+
+const licenseFileVersion = 1
+
+type licenseFilePayload struct {
+	Version      int                         `json:"version"`
+	LicenseID    string                      `json:"licenseId"`
+	KeyHash      string                      `json:"keyHash"`
+	Licensee     licenseeResponse            `json:"licensee"`
+	Plan         string                      `json:"plan"`
+	Trial        bool                        `json:"trial"`
+	Entitlements licenseEntitlementsResponse `json:"entitlements"`
+	IssuedAt     int64                       `json:"issuedAt"`
+	ExpiresAt    *int64                      `json:"expiresAt"`
+}
+
+func licenseFilePayloadFromModel(u *core.User, l *core.License, p *core.Plan, issuedAt time.Time) *licenseFilePayload {
+	var expiresAt *int64
+	if l.ExpiresAt != nil {
+		v := l.ExpiresAt.Unix()
+		expiresAt = &v
+	}
+	return &licenseFilePayload{
+		Version:      licenseFileVersion,
+		LicenseID:    l.ID.String(),
+		KeyHash:      l.KeyHash,
+		Licensee:     licenseeFromModel(u),
+		Plan:         p.Code,
+		Trial:        l.IsTrial,
+		Entitlements: licenseEntitlementsFromModel(p.Entitlements()),
+		IssuedAt:     issuedAt.Unix(),
+		ExpiresAt:    expiresAt,
+	}
+}
+
+func (s *Server) handleGetLicenseFile(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.db.User().GetByID(ctx, l.UserID)
+	if err != nil {
+		return err
+	}
+
+	doc, err := s.signer.Sign(licenseFilePayloadFromModel(owner, l, p, now))
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), licenseAuditEntry(core.AuditActionLicenseFileDownloaded, l)); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="sourcetool.lic"`)
+	return s.renderJSON(w, http.StatusOK, doc)
+}
+
+type rotateLicenseResponse struct {
+	License *licenseResponse `json:"license"`
+}
+
+func (s *Server) handleRotateLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		// Reload under lock so concurrent rotations or status changes cannot
+		// overwrite each other's key or status.
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+
+		plainLicenseKey, hashedLicenseKey, err := core.GenerateLicenseKey()
+		if err != nil {
+			return err
+		}
+
+		// Replacing the hash is what invalidates the old key, since keys are only ever looked up by hash.
+		// The license store also adds the old hash to the revocation list for offline installs.
+		l.KeyHash = hashedLicenseKey
+		if err := s.sealLicenseKey(l, []byte(plainLicenseKey)); err != nil {
+			return err
+		}
+
+		if err := tx.License().Update(ctx, l); err != nil {
+			return err
+		}
+
+		if err := s.recordAudit(r, tx.AuditEvent(), licenseAuditEntry(core.AuditActionLicenseKeyRotated, l)); err != nil {
+			return err
+		}
+
+		// Tell the licensee, who may not be the member that rotated the key.
+		owner, err := tx.User().GetByID(ctx, l.UserID)
+		if err != nil {
+			return err
+		}
+		if err := mail.SendLicenseKeyRotatedEmail(ctx, owner.Email, owner.FirstName); err != nil {
+			return err
+		}
+
 		return nil
+	}); err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, rotateLicenseResponse{
+		License: s.licenseFromModel(l, p),
+	})
+}
+
+type getLicensePublicKeyResponse struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId"`
+	PublicKey string `json:"publicKey"`
+}
+
+func (s *Server) handleGetLicensePublicKey(w http.ResponseWriter, r *http.Request) error {
+	pubPEM, err := s.signer.PublicKeyPEM()
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, getLicensePublicKeyResponse{
+		Algorithm: sign.Algorithm,
+		KeyID:     s.signer.KeyID(),
+		PublicKey: string(pubPEM),
+	})
+}
+
+type validateLicenseRequest struct {
+	Key string `json:"key" validate:"required"`
+}
+
+// validateLicenseResponse is returned to anyone holding a key, so it only says
+// whether the key may be used and what it unlocks, and nothing about the licensee.
+type validateLicenseResponse struct {
+	Valid        bool                        `json:"valid"`
+	Status       string                      `json:"status"`
+	ExpiresAt    *string                     `json:"expiresAt"`
+	Entitlements licenseEntitlementsResponse `json:"entitlements"`
+}
+
+func (s *Server) handleValidateLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req validateLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	l, err := s.db.License().GetByKeyHash(ctx, core.HashLicenseKey(req.Key))
+	if err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.renderJSON(w, http.StatusOK, validateLicenseResponse{
+		Valid:        l.IsValid(now),
+		Status:       string(l.EffectiveStatus(now)),
+		ExpiresAt:    formatUnixPtr(l.ExpiresAt),
+		Entitlements: licenseEntitlementsFromModel(p.Entitlements()),
+	})
+}
+
+type listLicensesResponse struct {
+	Licenses []*licenseResponse `json:"licenses"`
+}
+
+func (s *Server) handleListLicenses(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	ctxUser := internal.ContextUser(ctx)
+	licenses, err := s.db.License().List(ctx, database.LicenseByMemberUserID(ctxUser.ID))
+	if err != nil {
+		return err
+	}
+
+	planByID, err := s.listPlansByID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Keys are left out of the list; they are only returned, and audited, by
+	// handleGetLicense.
+	res := make([]*licenseResponse, 0, len(licenses))
+	for _, l := range licenses {
+		res = append(res, licenseSummaryFromModel(l, planByID[l.PlanID]))
+	}
+
+	return s.renderJSON(w, http.StatusOK, listLicensesResponse{
+		Licenses: res,
+	})
+}
+
+type getLicenseResponse struct {
+	License *licenseResponse `json:"license"`
+}
+
+func (s *Server) handleGetLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
 	}
 
-	return &licenseResponse{
-		ID:     l.ID.String(),
-		UserID: l.UserID.String(),
-		Key:    string(key),
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(r, s.db.AuditEvent(), licenseAuditEntry(core.AuditActionLicenseViewed, l)); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, getLicenseResponse{
+		License: s.licenseFromModel(l, p),
+	})
+}
+
+type createLicenseRequest struct {
+	OrganizationID string   `json:"organizationId" validate:"omitempty,uuid"`
+	Name           string   `json:"name" validate:"required,max=255"`
+	Labels         []string `json:"labels" validate:"max=20,dive,required,max=64"`
+}
+
+type createLicenseResponse struct {
+	License *licenseResponse `json:"license"`
+}
+
+func (s *Server) handleCreateLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req createLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
 	}
+
+	p, err := s.db.Plan().GetByCode(ctx, core.DefaultPlanCode)
+	if err != nil {
+		return err
+	}
+
+	ctxUser := internal.ContextUser(ctx)
+	o, err := s.organizationForNewLicense(ctx, ctxUser, req.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.authorizeMember(ctx, o.ID, ctxUser, core.PermissionLicenseWrite); err != nil {
+		return err
+	}
+
+	l, err := s.newLicense(o.ID, ctxUser.ID, p, req.Name, req.Labels)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.License().Create(ctx, l); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), licenseAuditEntry(core.AuditActionLicenseCreated, l))
+	}); err != nil {
+		return err
+	}
+
+	// Reload to pick up database defaults such as created_at.
+	l, err = s.db.License().GetByID(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, createLicenseResponse{
+		License: s.licenseFromModel(l, p),
+	})
+}
+
+type updateLicenseRequest struct {
+	Name   *string  `json:"name" validate:"omitempty,min=1,max=255"`
+	Labels []string `json:"labels" validate:"omitempty,max=20,dive,required,max=64"`
+}
+
+type updateLicenseResponse struct {
+	License *licenseResponse `json:"license"`
+}
+
+func (s *Server) handleUpdateLicense(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req updateLicenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		// Reload under lock so a concurrent rotation, status change or transfer
+		// is not overwritten with the values read above.
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+
+		if req.Name != nil {
+			l.Name = internal.StringValue(req.Name)
+		}
+		if req.Labels != nil {
+			l.Labels = req.Labels
+		}
+
+		return tx.License().Update(ctx, l)
+	}); err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, updateLicenseResponse{
+		License: s.licenseFromModel(l, p),
+	})
 }
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+const (
+	defaultRevocationPageSize = 100
+	maxRevocationPageSize     = 1000
+)
+
+const revocationListVersion = 2
+
+// revocationResponse only carries LicenseID when the whole license was revoked. A
+// rotated entry invalidates just the old key, so clients must match it by KeyHash
+// and keep accepting the license's current key.
+type revocationResponse struct {
+	LicenseID string `json:"licenseId,omitempty"`
+	KeyHash   string `json:"keyHash"`
+	Reason    string `json:"reason"`
+	RevokedAt int64  `json:"revokedAt"`
+}
+
+func revocationFromModel(rv *core.LicenseRevocation) *revocationResponse {
+	res := &revocationResponse{
+		KeyHash:   rv.KeyHash,
+		Reason:    string(rv.Reason),
+		RevokedAt: rv.RevokedAt.Unix(),
+	}
+	if rv.Reason == core.LicenseRevocationReasonRevoked {
+		res.LicenseID = rv.LicenseID.String()
+	}
+	return res
+}
+
+func formatRevocationCursor(c core.LicenseRevocationCursor) string {
+	return strconv.FormatUint(c.TxID, 10) + "." + strconv.FormatInt(c.Seq, 10)
+}
+
+func parseRevocationCursor(v string) (core.LicenseRevocationCursor, error) {
+	txID, seq, ok := strings.Cut(v, ".")
+	if !ok {
+		return core.LicenseRevocationCursor{}, errors.New("invalid cursor")
+	}
+
+	var c core.LicenseRevocationCursor
+	var err error
+	if c.TxID, err = strconv.ParseUint(txID, 10, 64); err != nil {
+		return core.LicenseRevocationCursor{}, errors.New("invalid cursor")
+	}
+	if c.Seq, err = strconv.ParseInt(seq, 10, 64); err != nil || c.Seq < 0 {
+		return core.LicenseRevocationCursor{}, errors.New("invalid cursor")
+	}
+
+	return c, nil
+}
+
+// revocationListPayload is signed as a whole, including the cursor, so that a
+// client cannot be tricked into skipping pages. NextCursor is always set, even on
+// the last page, and is where the client should resume its next sync; since is
+// only meant for the first one.
+type revocationListPayload struct {
+	Version     int                   `json:"version"`
+	IssuedAt    int64                 `json:"issuedAt"`
+	Since       int64                 `json:"since"`
+	Revocations []*revocationResponse `json:"revocations"`
+	NextCursor  string                `json:"nextCursor"`
+	HasMore     bool                  `json:"hasMore"`
+}
+
+func (s *Server) handleListLicenseRevocations(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := r.URL.Query()
+
+	var since int64
+	if v := q.Get("since"); v != "" {
+		var err error
+		since, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || since < 0 {
+			return errdefs.ErrInvalidArgument(errors.New("since must be a unix timestamp"))
+		}
+	}
+
+	var after core.LicenseRevocationCursor
+	if v := q.Get("cursor"); v != "" {
+		var err error
+		after, err = parseRevocationCursor(v)
+		if err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+	}
+
+	limit := uint64(defaultRevocationPageSize)
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n == 0 || n > maxRevocationPageSize {
+			return errdefs.ErrInvalidArgument(errors.New("limit must be between 1 and 1000"))
+		}
+		limit = n
+	}
+
+	revocations, err := s.db.License().ListRevocations(ctx, time.Unix(since, 0), after, limit)
+	if err != nil {
+		return err
+	}
+
+	items := make([]*revocationResponse, 0, len(revocations))
+	for _, rv := range revocations {
+		items = append(items, revocationFromModel(rv))
+	}
+
+	next := after
+	if len(revocations) > 0 {
+		next = revocations[len(revocations)-1].Cursor()
+	}
+
+	doc, err := s.signer.Sign(&revocationListPayload{
+		Version:     revocationListVersion,
+		IssuedAt:    time.Now().Unix(),
+		Since:       since,
+		Revocations: items,
+		NextCursor:  formatRevocationCursor(next),
+		HasMore:     uint64(len(revocations)) == limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, doc)
+}
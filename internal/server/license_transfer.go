@@ -0,0 +1,304 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/jwt"
+	"github.com/trysourcetool/onprem-portal/internal/mail"
+)
+
+func buildLicenseTransferURL(token string) (string, error) {
+	return internal.BuildURL(config.Config.BaseURL, path.Join("licenses", "transfers", "accept"), map[string]string{
+		"token": token,
+	})
+}
+
+type createLicenseTransferRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type createLicenseTransferResponse struct {
+	Email     string `json:"email"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// handleCreateLicenseTransfer emails a transfer token to the recipient. Only the current
+// licensee can hand the license over, and the transfer takes effect once the recipient
+// accepts it.
+func (s *Server) handleCreateLicenseTransfer(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req createLicenseTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	ctxUser := internal.ContextUser(ctx)
+	if l.UserID != ctxUser.ID {
+		return errdefs.ErrPermissionDenied(errors.New("only the licensee can transfer the license"))
+	}
+	if strings.EqualFold(req.Email, ctxUser.Email) {
+		return errdefs.ErrInvalidArgument(errors.New("license cannot be transferred to yourself"))
+	}
+	if l.Status == core.LicenseStatusRevoked {
+		return errdefs.ErrInvalidArgument(errors.New("revoked license cannot be transferred"))
+	}
+
+	expiresAt := time.Now().Add(core.LicenseTransferExpiration)
+	tok, err := jwt.SignLicenseTransferToken(l.ID.String(), l.UserID.String(), l.OrganizationID.String(), req.Email, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	url, err := buildLicenseTransferURL(tok)
+	if err != nil {
+		return err
+	}
+
+	if err := mail.SendLicenseTransferEmail(ctx, req.Email, ctxUser.FullName(), l.Name, url); err != nil {
+		return err
+	}
+
+	e := licenseAuditEntry(core.AuditActionLicenseTransferStarted, l)
+	e.Payload = map[string]any{"email": req.Email}
+	if err := s.recordAudit(r, s.db.AuditEvent(), e); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, createLicenseTransferResponse{
+		Email:     req.Email,
+		ExpiresAt: strconv.FormatInt(expiresAt.Unix(), 10),
+	})
+}
+
+type acceptLicenseTransferRequest struct {
+	Token string `json:"token" validate:"required"`
+	// OrganizationID is where the license moves to. It defaults to the recipient's
+	// first organization.
+	OrganizationID string `json:"organizationId" validate:"omitempty,uuid"`
+}
+
+type acceptLicenseTransferResponse struct {
+	License *licenseResponse `json:"license"`
+}
+
+// handleAcceptLicenseTransfer moves the license to the signed-in recipient and re-seals
+// its key for the new licensee. A token is only honored while the license still belongs
+// to the user and organization it was issued for, so it cannot be replayed once the
+// license has moved on.
+func (s *Server) handleAcceptLicenseTransfer(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req acceptLicenseTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	claims, err := jwt.ParseLicenseTransferClaims(req.Token)
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	ctxUser := internal.ContextUser(ctx)
+	if !strings.EqualFold(claims.Subject, ctxUser.Email) {
+		return errdefs.ErrInvalidArgument(errors.New("transfer was sent to a different email address"))
+	}
+
+	licenseID, err := uuid.FromString(claims.LicenseID)
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	o, err := s.organizationForNewLicense(ctx, ctxUser, req.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.authorizeMember(ctx, o.ID, ctxUser, core.PermissionLicenseWrite); err != nil {
+		return err
+	}
+
+	var l *core.License
+	var previousOwner *core.User
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, licenseID)
+		if err != nil {
+			return err
+		}
+		if l.UserID.String() != claims.FromUserID || l.OrganizationID.String() != claims.FromOrganizationID {
+			return errdefs.ErrInvalidArgument(errors.New("transfer is no longer valid"))
+		}
+
+		previousOwner, err = tx.User().GetByID(ctx, l.UserID)
+		if err != nil {
+			return err
+		}
+
+		plainKey, err := s.openLicenseKey(l)
+		if err != nil {
+			return err
+		}
+
+		fromOrganizationID := l.OrganizationID
+		if err := l.Transfer(o.ID, ctxUser.ID); err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+		if err := s.sealLicenseKey(l, plainKey); err != nil {
+			return err
+		}
+
+		if err := tx.License().Update(ctx, l); err != nil {
+			return err
+		}
+
+		// Record the transfer in both organizations' audit logs.
+		payload := map[string]any{
+			"fromUserId":         previousOwner.ID.String(),
+			"toUserId":           ctxUser.ID.String(),
+			"fromOrganizationId": fromOrganizationID.String(),
+			"toOrganizationId":   o.ID.String(),
+		}
+		organizationIDs := []uuid.UUID{o.ID}
+		if fromOrganizationID != o.ID {
+			organizationIDs = append(organizationIDs, fromOrganizationID)
+		}
+		for _, organizationID := range organizationIDs {
+			e := licenseAuditEntry(core.AuditActionLicenseTransferred, l)
+			e.OrganizationID = &organizationID
+			e.Payload = payload
+			if err := s.recordAudit(r, tx.AuditEvent(), e); err != nil {
+				return err
+			}
+		}
+
+		for _, u := range []*core.User{previousOwner, ctxUser} {
+			if err := mail.SendLicenseTransferredEmail(ctx, u.Email, u.FirstName, l.Name, previousOwner.Email, ctxUser.Email); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, acceptLicenseTransferResponse{
+		License: s.licenseFromModel(l, p),
+	})
+}
+
+type updateLicenseeRequest struct {
+	UserID string `json:"userId" validate:"required,uuid"`
+}
+
+type updateLicenseeResponse struct {
+	License *licenseResponse `json:"license"`
+}
+
+// handleUpdateLicensee makes another member of the license's organization its licensee,
+// e.g. when the engineer who signed up leaves the company. Unlike a transfer the license
+// stays in its organization, so it takes effect immediately.
+func (s *Server) handleUpdateLicensee(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req updateLicenseeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	userID, err := uuid.FromString(req.UserID)
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.Organization().GetMember(ctx, l.OrganizationID, userID); err != nil {
+		if errdefs.IsOrganizationMemberNotFound(err) {
+			return errdefs.ErrInvalidArgument(errors.New("licensee must be a member of the license's organization"))
+		}
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		var err error
+		l, err = tx.License().GetByIDForUpdate(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+
+		plainKey, err := s.openLicenseKey(l)
+		if err != nil {
+			return err
+		}
+
+		previousUserID := l.UserID
+		if err := l.Transfer(l.OrganizationID, userID); err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+		if err := s.sealLicenseKey(l, plainKey); err != nil {
+			return err
+		}
+
+		if err := tx.License().Update(ctx, l); err != nil {
+			return err
+		}
+
+		e := licenseAuditEntry(core.AuditActionLicenseLicenseeChanged, l)
+		e.Payload = map[string]any{
+			"fromUserId": previousUserID.String(),
+			"toUserId":   userID.String(),
+		}
+		return s.recordAudit(r, tx.AuditEvent(), e)
+	}); err != nil {
+		return err
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, updateLicenseeResponse{
+		License: s.licenseFromModel(l, p),
+	})
+}
@@ -3,8 +3,12 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/gofrs/uuid/v5"
 
 	"github.com/trysourcetool/onprem-portal/internal"
@@ -90,3 +94,112 @@ func (s *Server) authUser(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// authStaff must be installed after authUser. It restricts the route to staff users.
+func (s *Server) authStaff(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !internal.ContextUser(r.Context()).IsStaff {
+			s.serveError(w, r, errdefs.ErrPermissionDenied(errors.New("staff only")))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// organizationIDFromRequest resolves the organization a request acts on: the one named by
+// the organizationID URL parameter, or the owner of the license the request targets.
+func (s *Server) organizationIDFromRequest(r *http.Request) (uuid.UUID, error) {
+	if param := chi.URLParam(r, "organizationID"); param != "" {
+		organizationID, err := uuid.FromString(param)
+		if err != nil {
+			return uuid.Nil, errdefs.ErrInvalidArgument(err)
+		}
+		return organizationID, nil
+	}
+
+	l, err := s.licenseFromRequest(r)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return l.OrganizationID, nil
+}
+
+// authorizeMember returns u's membership in the organization if their role grants p.
+func (s *Server) authorizeMember(ctx context.Context, organizationID uuid.UUID, u *core.User, p core.Permission) (*core.OrganizationMember, error) {
+	m, err := s.db.Organization().GetMember(ctx, organizationID, u.ID)
+	if err != nil {
+		if errdefs.IsOrganizationMemberNotFound(err) {
+			return nil, errdefs.ErrOrganizationNotFound(errors.New("user is not a member of the organization"))
+		}
+		return nil, err
+	}
+
+	if !m.Role.Can(p) {
+		return nil, errdefs.ErrPermissionDenied(fmt.Errorf("%s role does not have %s permission", m.Role, p))
+	}
+
+	return m, nil
+}
+
+// requirePermission must be installed after authUser. It rejects requests from members
+// whose role in the target organization does not grant p.
+func (s *Server) requirePermission(p core.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			organizationID, err := s.organizationIDFromRequest(r)
+			if err != nil {
+				s.serveError(w, r, err)
+				return
+			}
+
+			if _, err := s.authorizeMember(ctx, organizationID, internal.ContextUser(ctx), p); err != nil {
+				s.serveError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticateLicense authenticates an on-prem instance by the license key it
+// sends as a bearer token.
+func (s *Server) authenticateLicense(r *http.Request) (*core.License, error) {
+	ctx := r.Context()
+
+	authHeader := r.Header.Get("Authorization")
+	key, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || key == "" {
+		return nil, errdefs.ErrUnauthenticated(errors.New("failed to get license key"))
+	}
+
+	l, err := s.db.License().GetByKeyHash(ctx, core.HashLicenseKey(key))
+	if err != nil {
+		return nil, errdefs.ErrUnauthenticated(err)
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return nil, errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	return l, nil
+}
+
+func (s *Server) authLicense(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		l, err := s.authenticateLicense(r)
+		if err != nil {
+			s.serveError(w, r, err)
+			return
+		}
+
+		ctx = context.WithValue(ctx, internal.ContextLicenseKey, l)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
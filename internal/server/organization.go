@@ -0,0 +1,310 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+type organizationResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+func organizationFromModel(o *core.Organization) *organizationResponse {
+	if o == nil {
+		return nil
+	}
+
+	return &organizationResponse{
+		ID:        o.ID.String(),
+		Name:      o.Name,
+		CreatedAt: strconv.FormatInt(o.CreatedAt.Unix(), 10),
+		UpdatedAt: strconv.FormatInt(o.UpdatedAt.Unix(), 10),
+	}
+}
+
+type organizationMemberResponse struct {
+	ID        string `json:"id"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+}
+
+func organizationMemberFromModel(m *core.OrganizationMember, u *core.User) *organizationMemberResponse {
+	if m == nil || u == nil {
+		return nil
+	}
+
+	return &organizationMemberResponse{
+		ID:        m.ID.String(),
+		UserID:    u.ID.String(),
+		Email:     u.Email,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Role:      string(m.Role),
+		CreatedAt: strconv.FormatInt(m.CreatedAt.Unix(), 10),
+	}
+}
+
+// newPersonalOrganization builds the organization every new user starts with,
+// along with their membership in it.
+func newPersonalOrganization(u *core.User) (*core.Organization, *core.OrganizationMember) {
+	o := &core.Organization{
+		ID:   uuid.Must(uuid.NewV4()),
+		Name: u.FullName(),
+	}
+	m := &core.OrganizationMember{
+		ID:             uuid.Must(uuid.NewV4()),
+		OrganizationID: o.ID,
+		UserID:         u.ID,
+		Role:           core.OrganizationRoleOwner,
+	}
+	return o, m
+}
+
+// organizationForNewLicense resolves the organization a new license is issued to.
+// An empty id falls back to the user's first organization.
+func (s *Server) organizationForNewLicense(ctx context.Context, u *core.User, id string) (*core.Organization, error) {
+	if id == "" {
+		organizations, err := s.db.Organization().ListByUserID(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(organizations) == 0 {
+			return nil, errdefs.ErrOrganizationNotFound(errors.New("user has no organization"))
+		}
+		return organizations[0], nil
+	}
+
+	organizationID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	return s.getMemberOrganization(ctx, u, organizationID)
+}
+
+// getMemberOrganization returns the organization only if u is a member of it.
+func (s *Server) getMemberOrganization(ctx context.Context, u *core.User, organizationID uuid.UUID) (*core.Organization, error) {
+	if _, err := s.db.Organization().GetMember(ctx, organizationID, u.ID); err != nil {
+		if errdefs.IsOrganizationMemberNotFound(err) {
+			return nil, errdefs.ErrOrganizationNotFound(errors.New("user is not a member of the organization"))
+		}
+		return nil, err
+	}
+
+	return s.db.Organization().GetByID(ctx, organizationID)
+}
+
+func (s *Server) organizationFromRequest(r *http.Request) (*core.Organization, error) {
+	ctx := r.Context()
+
+	organizationID, err := uuid.FromString(chi.URLParam(r, "organizationID"))
+	if err != nil {
+		return nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	return s.getMemberOrganization(ctx, internal.ContextUser(ctx), organizationID)
+}
+
+type listOrganizationsResponse struct {
+	Organizations []*organizationResponse `json:"organizations"`
+}
+
+func (s *Server) handleListOrganizations(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	ctxUser := internal.ContextUser(ctx)
+	organizations, err := s.db.Organization().ListByUserID(ctx, ctxUser.ID)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*organizationResponse, 0, len(organizations))
+	for _, o := range organizations {
+		res = append(res, organizationFromModel(o))
+	}
+
+	return s.renderJSON(w, http.StatusOK, listOrganizationsResponse{
+		Organizations: res,
+	})
+}
+
+type getOrganizationResponse struct {
+	Organization *organizationResponse `json:"organization"`
+}
+
+func (s *Server) handleGetOrganization(w http.ResponseWriter, r *http.Request) error {
+	o, err := s.organizationFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, getOrganizationResponse{
+		Organization: organizationFromModel(o),
+	})
+}
+
+type updateOrganizationRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=255"`
+}
+
+type updateOrganizationResponse struct {
+	Organization *organizationResponse `json:"organization"`
+}
+
+func (s *Server) handleUpdateOrganization(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req updateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+	// The name is embedded in generated configuration files, where a line break
+	// could smuggle in extra settings.
+	if req.Name != nil && strings.ContainsFunc(*req.Name, unicode.IsControl) {
+		return errdefs.ErrInvalidArgument(errors.New("name must not contain control characters"))
+	}
+
+	o, err := s.organizationFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		o.Name = internal.StringValue(req.Name)
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		return tx.Organization().Update(ctx, o)
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, updateOrganizationResponse{
+		Organization: organizationFromModel(o),
+	})
+}
+
+type listOrganizationMembersResponse struct {
+	Members []*organizationMemberResponse `json:"members"`
+}
+
+func (s *Server) handleListOrganizationMembers(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	o, err := s.organizationFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.db.Organization().ListMembers(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*organizationMemberResponse, 0, len(members))
+	for _, m := range members {
+		u, err := s.db.User().GetByID(ctx, m.UserID)
+		if err != nil {
+			return err
+		}
+		res = append(res, organizationMemberFromModel(m, u))
+	}
+
+	return s.renderJSON(w, http.StatusOK, listOrganizationMembersResponse{
+		Members: res,
+	})
+}
+
+type updateOrganizationMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin billing viewer"`
+}
+
+type updateOrganizationMemberResponse struct {
+	Member *organizationMemberResponse `json:"member"`
+}
+
+func (s *Server) handleUpdateOrganizationMember(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req updateOrganizationMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	userID, err := uuid.FromString(chi.URLParam(r, "userID"))
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	o, err := s.organizationFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	// Owners cannot demote themselves, so every organization keeps at least one owner.
+	ctxUser := internal.ContextUser(ctx)
+	if userID == ctxUser.ID {
+		return errdefs.ErrPermissionDenied(errors.New("cannot change your own role"))
+	}
+
+	m, err := s.db.Organization().GetMember(ctx, o.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.db.User().GetByID(ctx, m.UserID)
+	if err != nil {
+		return err
+	}
+
+	oldRole := m.Role
+	m.Role = core.OrganizationRole(req.Role)
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		if err := tx.Organization().UpdateMember(ctx, m); err != nil {
+			return err
+		}
+
+		return s.recordAudit(r, tx.AuditEvent(), auditEntry{
+			Action:         core.AuditActionOrganizationMemberRoleUpdated,
+			OrganizationID: &o.ID,
+			TargetType:     core.AuditTargetUser,
+			TargetID:       u.ID.String(),
+			Payload:        map[string]any{"oldRole": oldRole, "newRole": m.Role},
+		})
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, updateOrganizationMemberResponse{
+		Member: organizationMemberFromModel(m, u),
+	})
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/jwt"
+	"github.com/trysourcetool/onprem-portal/internal/mail"
+)
+
+func buildOrganizationInvitationURL(token string) (string, error) {
+	return internal.BuildURL(config.Config.BaseURL, path.Join("organizations", "invitations", "accept"), map[string]string{
+		"token": token,
+	})
+}
+
+// parseOrganizationInvitation validates an invitation token for email and returns
+// the organization it grants access to along with the invited role.
+func (s *Server) parseOrganizationInvitation(ctx context.Context, token, email string) (*core.Organization, core.OrganizationRole, error) {
+	claims, err := jwt.ParseOrganizationInvitationClaims(token)
+	if err != nil {
+		return nil, "", errdefs.ErrInvalidArgument(err)
+	}
+
+	if !strings.EqualFold(claims.Subject, email) {
+		return nil, "", errdefs.ErrInvalidArgument(errors.New("invitation was sent to a different email address"))
+	}
+
+	organizationID, err := uuid.FromString(claims.OrganizationID)
+	if err != nil {
+		return nil, "", errdefs.ErrInvalidArgument(err)
+	}
+
+	o, err := s.db.Organization().GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return o, core.OrganizationRole(claims.Role), nil
+}
+
+// signUp holds the records created alongside a new user.
+type signUp struct {
+	// organization is nil when the user joins an existing organization.
+	organization *core.Organization
+	member       *core.OrganizationMember
+	// license is nil when the user joins an existing organization, which already has one.
+	license *core.License
+}
+
+// newSignUp decides where a newly registered user lands. With an invitation token they
+// join the inviting organization; otherwise they get a personal organization and a
+// trial license on the default plan.
+func (s *Server) newSignUp(ctx context.Context, u *core.User, invitationToken string) (*signUp, error) {
+	if invitationToken != "" {
+		o, role, err := s.parseOrganizationInvitation(ctx, invitationToken, u.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		return &signUp{
+			member: &core.OrganizationMember{
+				ID:             uuid.Must(uuid.NewV4()),
+				OrganizationID: o.ID,
+				UserID:         u.ID,
+				Role:           role,
+			},
+		}, nil
+	}
+
+	plan, err := s.db.Plan().GetByCode(ctx, core.DefaultPlanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	o, m := newPersonalOrganization(u)
+	l, err := s.newLicense(o.ID, u.ID, plan, core.DefaultLicenseName, nil)
+	if err != nil {
+		return nil, errdefs.ErrInternal(fmt.Errorf("failed to issue license: %w", err))
+	}
+	if days := config.Config.Trial.Days; days > 0 {
+		l.StartTrial(time.Duration(days)*24*time.Hour, time.Now())
+	}
+
+	return &signUp{
+		organization: o,
+		member:       m,
+		license:      l,
+	}, nil
+}
+
+func (su *signUp) create(ctx context.Context, tx database.Tx) error {
+	if su.organization != nil {
+		if err := tx.Organization().Create(ctx, su.organization); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Organization().CreateMember(ctx, su.member); err != nil {
+		return err
+	}
+
+	if su.license != nil {
+		if err := tx.License().Create(ctx, su.license); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type createOrganizationInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner admin billing viewer"`
+}
+
+type createOrganizationInvitationResponse struct {
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+func (s *Server) handleCreateOrganizationInvitation(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req createOrganizationInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	o, err := s.organizationFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	// Only members who can manage roles may hand out ownership.
+	ctxUser := internal.ContextUser(ctx)
+	role := core.OrganizationRole(req.Role)
+	if role == core.OrganizationRoleOwner {
+		if _, err := s.authorizeMember(ctx, o.ID, ctxUser, core.PermissionMemberManage); err != nil {
+			return err
+		}
+	}
+
+	exists, err := s.db.User().IsEmailExists(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		invitee, err := s.db.User().GetByEmail(ctx, req.Email)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.db.Organization().GetMember(ctx, o.ID, invitee.ID)
+		if err == nil {
+			return errdefs.ErrAlreadyExists(errors.New("user is already a member of the organization"))
+		}
+		if !errdefs.IsOrganizationMemberNotFound(err) {
+			return err
+		}
+	}
+
+	expiresAt := time.Now().Add(core.InvitationExpiration)
+	tok, err := jwt.SignOrganizationInvitationToken(o.ID.String(), req.Role, req.Email, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	url, err := buildOrganizationInvitationURL(tok)
+	if err != nil {
+		return err
+	}
+
+	if err := mail.SendOrganizationInvitationEmail(ctx, req.Email, ctxUser.FullName(), o.Name, url); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusCreated, createOrganizationInvitationResponse{
+		Email:     req.Email,
+		Role:      req.Role,
+		ExpiresAt: strconv.FormatInt(expiresAt.Unix(), 10),
+	})
+}
+
+type acceptOrganizationInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type acceptOrganizationInvitationResponse struct {
+	Organization *organizationResponse `json:"organization"`
+}
+
+// handleAcceptOrganizationInvitation lets users who already have an account join
+// the inviting organization. New users accept through the register endpoints instead.
+func (s *Server) handleAcceptOrganizationInvitation(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var req acceptOrganizationInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	ctxUser := internal.ContextUser(ctx)
+	o, role, err := s.parseOrganizationInvitation(ctx, req.Token, ctxUser.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithTx(ctx, func(tx database.Tx) error {
+		return tx.Organization().CreateMember(ctx, &core.OrganizationMember{
+			ID:             uuid.Must(uuid.NewV4()),
+			OrganizationID: o.ID,
+			UserID:         ctxUser.ID,
+			Role:           role,
+		})
+	}); err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, acceptOrganizationInvitationResponse{
+		Organization: organizationFromModel(o),
+	})
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type paginationResponse struct {
+	Total  int64  `json:"total"`
+	Limit  uint64 `json:"limit"`
+	Offset uint64 `json:"offset"`
+}
+
+func parsePagination(r *http.Request) (limit, offset uint64, err error) {
+	q := r.URL.Query()
+
+	limit = defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n == 0 || n > maxPageSize {
+			return 0, 0, errdefs.ErrInvalidArgument(errors.New("limit must be between 1 and 200"))
+		}
+		limit = n
+	}
+
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, 0, errdefs.ErrInvalidArgument(errors.New("invalid offset"))
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+)
+
+type planResponse struct {
+	ID      string `json:"id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Edition string `json:"edition"`
+}
+
+func planFromModel(p *core.Plan) *planResponse {
+	if p == nil {
+		return nil
+	}
+
+	return &planResponse{
+		ID:      p.ID.String(),
+		Code:    p.Code,
+		Name:    p.Name,
+		Edition: p.Edition,
+	}
+}
+
+type catalogPlanResponse struct {
+	*planResponse
+	Entitlements licenseEntitlementsResponse `json:"entitlements"`
+}
+
+type listPlansResponse struct {
+	Plans []*catalogPlanResponse `json:"plans"`
+}
+
+func (s *Server) handleListPlans(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	plans, err := s.db.Plan().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	res := make([]*catalogPlanResponse, 0, len(plans))
+	for _, p := range plans {
+		res = append(res, &catalogPlanResponse{
+			planResponse: planFromModel(p),
+			Entitlements: licenseEntitlementsFromModel(p.Entitlements()),
+		})
+	}
+
+	return s.renderJSON(w, http.StatusOK, listPlansResponse{
+		Plans: res,
+	})
+}
+
+func (s *Server) listPlansByID(ctx context.Context) (map[uuid.UUID]*core.Plan, error) {
+	plans, err := s.db.Plan().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	planByID := make(map[uuid.UUID]*core.Plan, len(plans))
+	for _, p := range plans {
+		planByID[p.ID] = p
+	}
+	return planByID, nil
+}
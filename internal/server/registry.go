@@ -0,0 +1,82 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/registry"
+)
+
+type registryTokenResponse struct {
+	Token string `json:"token"`
+	// AccessToken duplicates Token for OAuth 2 compatible clients.
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	IssuedAt    string `json:"issued_at"`
+}
+
+// handleRegistryToken implements the Docker Registry v2 token endpoint. Clients
+// authenticate with HTTP Basic auth using any username and the license key as the
+// password, as in `docker login -u license -p <key> portal.example.com`, and receive
+// a pull token limited to the repositories the license is entitled to.
+func (s *Server) handleRegistryToken(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	if s.registry == nil {
+		return errdefs.ErrNotFound(errors.New("registry token service is not configured"))
+	}
+
+	q := r.URL.Query()
+	service := q.Get("service")
+	if want := s.registry.Service(); want != "" && service != want {
+		return errdefs.ErrInvalidArgument(fmt.Errorf("unknown service %q", service))
+	}
+
+	_, key, ok := r.BasicAuth()
+	if !ok || key == "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Sourcetool"`)
+		return errdefs.ErrUnauthenticated(errors.New("license key is required"))
+	}
+
+	l, err := s.db.License().GetByKeyHash(ctx, core.HashLicenseKey(key))
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Sourcetool"`)
+		return errdefs.ErrUnauthenticated(err)
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+
+	// docker login requests a token without a scope just to check the credentials.
+	requested := make([]*registry.Access, 0, len(q["scope"]))
+	for _, scope := range q["scope"] {
+		a, err := registry.ParseScope(scope)
+		if err != nil {
+			return errdefs.ErrInvalidArgument(err)
+		}
+		requested = append(requested, a)
+	}
+
+	token, err := s.registry.Issue(l.ID.String(), service, s.registry.Authorize(requested, p.Entitlements()), now)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, registryTokenResponse{
+		Token:       token,
+		AccessToken: token,
+		ExpiresIn:   int(registry.TokenExpiration.Seconds()),
+		IssuedAt:    now.UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,389 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/urlsign"
+)
+
+type releaseArtifactResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	CreatedAt string `json:"createdAt"`
+}
+
+func releaseArtifactFromModel(a *core.ReleaseArtifact) *releaseArtifactResponse {
+	if a == nil {
+		return nil
+	}
+
+	return &releaseArtifactResponse{
+		ID:        a.ID.String(),
+		Name:      a.Name,
+		Size:      a.Size,
+		SHA256:    a.SHA256,
+		CreatedAt: strconv.FormatInt(a.CreatedAt.Unix(), 10),
+	}
+}
+
+type releaseResponse struct {
+	ID         string                     `json:"id"`
+	Product    string                     `json:"product"`
+	Version    string                     `json:"version"`
+	Channel    string                     `json:"channel"`
+	Notes      string                     `json:"notes"`
+	Editions   []string                   `json:"editions"`
+	ReleasedAt string                     `json:"releasedAt"`
+	Artifacts  []*releaseArtifactResponse `json:"artifacts"`
+	CreatedAt  string                     `json:"createdAt"`
+	UpdatedAt  string                     `json:"updatedAt"`
+}
+
+func releaseFromModel(r *core.Release, artifacts []*core.ReleaseArtifact) *releaseResponse {
+	if r == nil {
+		return nil
+	}
+
+	editions := []string(r.Editions)
+	if editions == nil {
+		editions = []string{}
+	}
+
+	artifactsRes := make([]*releaseArtifactResponse, 0, len(artifacts))
+	for _, a := range artifacts {
+		artifactsRes = append(artifactsRes, releaseArtifactFromModel(a))
+	}
+
+	return &releaseResponse{
+		ID:         r.ID.String(),
+		Product:    r.Product,
+		Version:    r.Version,
+		Channel:    r.Channel,
+		Notes:      r.Notes,
+		Editions:   editions,
+		ReleasedAt: strconv.FormatInt(r.ReleasedAt.Unix(), 10),
+		Artifacts:  artifactsRes,
+		CreatedAt:  strconv.FormatInt(r.CreatedAt.Unix(), 10),
+		UpdatedAt:  strconv.FormatInt(r.UpdatedAt.Unix(), 10),
+	}
+}
+
+// releasesFromModels renders releases along with their artifacts, loaded in one query.
+func (s *Server) releasesFromModels(r *http.Request, releases []*core.Release) ([]*releaseResponse, error) {
+	ids := make([]uuid.UUID, 0, len(releases))
+	for _, rel := range releases {
+		ids = append(ids, rel.ID)
+	}
+
+	artifacts, err := s.db.Release().ListArtifacts(r.Context(), ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactsByReleaseID := make(map[uuid.UUID][]*core.ReleaseArtifact)
+	for _, a := range artifacts {
+		artifactsByReleaseID[a.ReleaseID] = append(artifactsByReleaseID[a.ReleaseID], a)
+	}
+
+	res := make([]*releaseResponse, 0, len(releases))
+	for _, rel := range releases {
+		res = append(res, releaseFromModel(rel, artifactsByReleaseID[rel.ID]))
+	}
+
+	return res, nil
+}
+
+// entitledLicenseFromRequest resolves the license whose entitlements gate the release
+// catalog: the one named by the licenseId query parameter, or the user's primary license.
+func (s *Server) entitledLicenseFromRequest(r *http.Request) (*core.License, *core.Plan, error) {
+	ctx := r.Context()
+	ctxUser := internal.ContextUser(ctx)
+
+	licenseID, ok, err := uuidFromQuery(r, "licenseId")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var l *core.License
+	if ok {
+		l, err = s.db.License().GetByID(ctx, licenseID)
+	} else {
+		l, err = s.getPrimaryLicense(ctx, ctxUser)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.authorizeMember(ctx, l.OrganizationID, ctxUser, core.PermissionLicenseRead); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return nil, nil, errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return l, p, nil
+}
+
+type listReleasesResponse struct {
+	Releases   []*releaseResponse `json:"releases"`
+	Pagination paginationResponse `json:"pagination"`
+}
+
+// handleListReleases lists the releases the caller's license is entitled to, newest first.
+func (s *Server) handleListReleases(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return err
+	}
+
+	_, p, err := s.entitledLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	q := r.URL.Query()
+	queries := []database.ReleaseQuery{
+		database.ReleaseByEdition(p.Edition),
+		database.ReleaseLimit(limit),
+		database.ReleaseOffset(offset),
+	}
+	if product := q.Get("product"); product != "" {
+		queries = append(queries, database.ReleaseByProduct(product))
+	}
+	if channel := q.Get("channel"); channel != "" {
+		queries = append(queries, database.ReleaseByChannel(channel))
+	}
+
+	releases, err := s.db.Release().List(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.db.Release().Count(ctx, queries...)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.releasesFromModels(r, releases)
+	if err != nil {
+		return err
+	}
+
+	return s.renderJSON(w, http.StatusOK, listReleasesResponse{
+		Releases: res,
+		Pagination: paginationResponse{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
+
+// releaseArtifactFromRequest returns the artifact named by the URL and the release it belongs to.
+func (s *Server) releaseArtifactFromRequest(r *http.Request) (*core.Release, *core.ReleaseArtifact, error) {
+	ctx := r.Context()
+
+	releaseID, err := uuid.FromString(chi.URLParam(r, "releaseID"))
+	if err != nil {
+		return nil, nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	artifactID, err := uuid.FromString(chi.URLParam(r, "artifactID"))
+	if err != nil {
+		return nil, nil, errdefs.ErrInvalidArgument(err)
+	}
+
+	rel, err := s.db.Release().GetByID(ctx, releaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := s.db.Release().GetArtifact(ctx, rel.ID, artifactID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rel, a, nil
+}
+
+func (s *Server) handleDownloadReleaseArtifact(w http.ResponseWriter, r *http.Request) error {
+	l, p, err := s.entitledLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	rel, a, err := s.releaseArtifactFromRequest(r)
+	if err != nil {
+		return err
+	}
+	if !rel.IsEntitled(p.Entitlements()) {
+		return errdefs.ErrReleaseNotFound(errors.New("license is not entitled to the release"))
+	}
+
+	return s.serveReleaseArtifact(w, r, l, a)
+}
+
+// releaseArtifactDownloadPath is the path of the signed download URL for a.
+func releaseArtifactDownloadPath(a *core.ReleaseArtifact) string {
+	return path.Join("/api/v1/downloads", a.ReleaseID.String(), a.ID.String())
+}
+
+type createReleaseArtifactDownloadURLResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// handleCreateReleaseArtifactDownloadURL issues an expiring URL that downloads the
+// artifact without a session, tied to the caller's license.
+func (s *Server) handleCreateReleaseArtifactDownloadURL(w http.ResponseWriter, r *http.Request) error {
+	l, p, err := s.entitledLicenseFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	rel, a, err := s.releaseArtifactFromRequest(r)
+	if err != nil {
+		return err
+	}
+	if !rel.IsEntitled(p.Entitlements()) {
+		return errdefs.ErrReleaseNotFound(errors.New("license is not entitled to the release"))
+	}
+
+	expiresAt := time.Now().Add(core.DownloadURLExpiration)
+	downloadPath := releaseArtifactDownloadPath(a)
+	query := urlsign.Sign(downloadPath, url.Values{"license": {l.ID.String()}}, expiresAt)
+
+	u, err := url.Parse(config.Config.BaseURL)
+	if err != nil {
+		return err
+	}
+	u.Path = downloadPath
+	u.RawQuery = query
+
+	return s.renderJSON(w, http.StatusCreated, createReleaseArtifactDownloadURLResponse{
+		URL:       u.String(),
+		ExpiresAt: strconv.FormatInt(expiresAt.Unix(), 10),
+	})
+}
+
+// handleDownloadSignedReleaseArtifact serves an artifact to the holder of a signed URL.
+// The license is checked again on every request, so suspending or revoking it also
+// invalidates URLs that were already handed out.
+func (s *Server) handleDownloadSignedReleaseArtifact(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	rel, a, err := s.releaseArtifactFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	if err := urlsign.Verify(releaseArtifactDownloadPath(a), query, time.Now()); err != nil {
+		return err
+	}
+
+	licenseID, err := uuid.FromString(query.Get("license"))
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	l, err := s.db.License().GetByID(ctx, licenseID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !l.IsValid(now) {
+		return errdefs.ErrLicenseNotActive(fmt.Errorf("license is %s", l.EffectiveStatus(now)))
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
+	if err != nil {
+		return err
+	}
+	if !rel.IsEntitled(p.Entitlements()) {
+		return errdefs.ErrReleaseNotFound(errors.New("license is not entitled to the release"))
+	}
+
+	return s.serveReleaseArtifact(w, r, l, a)
+}
+
+func releaseArtifactPath(a *core.ReleaseArtifact) string {
+	return filepath.Join(config.Config.Artifacts.Dir, a.Path)
+}
+
+// maxDownloadRangeLen bounds the Range header recorded with a download.
+const maxDownloadRangeLen = 255
+
+// serveReleaseArtifact records the download against l and streams the artifact file
+// from the artifacts directory. Range requests are supported so interrupted downloads
+// can be resumed.
+func (s *Server) serveReleaseArtifact(w http.ResponseWriter, r *http.Request, l *core.License, a *core.ReleaseArtifact) error {
+	ctx := r.Context()
+
+	f, err := os.Open(releaseArtifactPath(a))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errdefs.ErrReleaseArtifactNotFound(err)
+		}
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// HEAD requests only probe the file, e.g. from download managers, so they are
+	// not counted as downloads.
+	if r.Method != http.MethodHead {
+		byteRange := r.Header.Get("Range")
+		if len(byteRange) > maxDownloadRangeLen {
+			byteRange = byteRange[:maxDownloadRangeLen]
+		}
+		if err := s.db.Release().CreateDownload(ctx, &core.ReleaseDownload{
+			ID:         uuid.Must(uuid.NewV4()),
+			ArtifactID: a.ID,
+			LicenseID:  l.ID,
+			Range:      byteRange,
+			IPAddress:  clientIP(r),
+			UserAgent:  r.UserAgent(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("X-Checksum-Sha256", a.SHA256)
+	http.ServeContent(w, r, a.Name, fi.ModTime(), f)
+	return nil
+}
@@ -14,19 +14,25 @@ import (
 
 	"github.com/trysourcetool/onprem-portal/internal"
 	"github.com/trysourcetool/onprem-portal/internal/config"
+	"github.com/trysourcetool/onprem-portal/internal/core"
 	"github.com/trysourcetool/onprem-portal/internal/database"
 	"github.com/trysourcetool/onprem-portal/internal/encrypt"
 	"github.com/trysourcetool/onprem-portal/internal/errdefs"
 	"github.com/trysourcetool/onprem-portal/internal/logger"
+	"github.com/trysourcetool/onprem-portal/internal/registry"
+	"github.com/trysourcetool/onprem-portal/internal/sign"
 )
 
 type Server struct {
 	db        database.DB
 	encryptor *encrypt.Encryptor
+	signer    *sign.Signer
+	// registry is nil when the registry token service is not configured.
+	registry *registry.TokenIssuer
 }
 
-func New(db database.DB, encryptor *encrypt.Encryptor) *Server {
-	return &Server{db, encryptor}
+func New(db database.DB, encryptor *encrypt.Encryptor, signer *sign.Signer, registry *registry.TokenIssuer) *Server {
+	return &Server{db, encryptor, signer, registry}
 }
 
 func (s *Server) installDefaultMiddlewares(router *chi.Mux) {
@@ -43,6 +49,7 @@ func (s *Server) installCORSMiddleware(router *chi.Mux) {
 		},
 		AllowedMethods: []string{
 			http.MethodGet,
+			http.MethodHead,
 			http.MethodPost,
 			http.MethodPut,
 			http.MethodDelete,
@@ -64,6 +71,23 @@ func (s *Server) errorHandler(f func(w http.ResponseWriter, r *http.Request) err
 	}
 }
 
+// installLicenseHandlers installs the routes that operate on a single license. They are
+// mounted under /licenses/{licenseID} and, for the user's primary license, /users/me/license.
+func (s *Server) installLicenseHandlers(r chi.Router) {
+	read := r.With(s.requirePermission(core.PermissionLicenseRead))
+	write := r.With(s.requirePermission(core.PermissionLicenseWrite))
+
+	read.Get("/file", s.errorHandler(s.handleGetLicenseFile))
+	read.Get("/bundle", s.errorHandler(s.handleGetLicenseBundle))
+	write.Post("/rotate", s.errorHandler(s.handleRotateLicense))
+	write.Post("/transfer", s.errorHandler(s.handleCreateLicenseTransfer))
+	write.Put("/licensee", s.errorHandler(s.handleUpdateLicensee))
+	read.Get("/activations", s.errorHandler(s.handleListLicenseActivations))
+	write.Post("/activations/offline", s.errorHandler(s.handleActivateLicenseOffline))
+	write.Delete("/activations/{activationID}", s.errorHandler(s.handleDeactivateLicenseActivation))
+	read.Get("/activations/{activationID}/usage", s.errorHandler(s.handleGetLicenseActivationUsage))
+}
+
 func (s *Server) installRESTHandlers(router *chi.Mux) {
 	router.Route("/api", func(r chi.Router) {
 		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -86,6 +110,113 @@ func (s *Server) installRESTHandlers(router *chi.Mux) {
 				r.Post("/logout", s.errorHandler(s.handleLogout))
 			})
 
+			r.Get("/plans", s.errorHandler(s.handleListPlans))
+
+			r.Route("/licenses", func(r chi.Router) {
+				r.Get("/public-key", s.errorHandler(s.handleGetLicensePublicKey))
+				r.Post("/validate", s.errorHandler(s.handleValidateLicense))
+				r.Get("/revocations", s.errorHandler(s.handleListLicenseRevocations))
+				r.Post("/activate", s.errorHandler(s.handleActivateLicense))
+
+				r.Group(func(r chi.Router) {
+					r.Use(s.authUser)
+
+					r.Get("/", s.errorHandler(s.handleListLicenses))
+					r.Post("/", s.errorHandler(s.handleCreateLicense))
+					r.Post("/transfers/accept", s.errorHandler(s.handleAcceptLicenseTransfer))
+
+					r.Route("/{licenseID}", func(r chi.Router) {
+						r.With(s.requirePermission(core.PermissionLicenseRead)).Get("/", s.errorHandler(s.handleGetLicense))
+						r.With(s.requirePermission(core.PermissionLicenseWrite)).Put("/", s.errorHandler(s.handleUpdateLicense))
+						s.installLicenseHandlers(r)
+					})
+				})
+			})
+
+			r.Route("/organizations", func(r chi.Router) {
+				r.Use(s.authUser)
+
+				r.Get("/", s.errorHandler(s.handleListOrganizations))
+				r.Post("/invitations/accept", s.errorHandler(s.handleAcceptOrganizationInvitation))
+
+				r.Route("/{organizationID}", func(r chi.Router) {
+					r.Get("/", s.errorHandler(s.handleGetOrganization))
+					r.With(s.requirePermission(core.PermissionOrganizationUpdate)).Put("/", s.errorHandler(s.handleUpdateOrganization))
+					r.Get("/members", s.errorHandler(s.handleListOrganizationMembers))
+					r.With(s.requirePermission(core.PermissionMemberManage)).Put("/members/{userID}", s.errorHandler(s.handleUpdateOrganizationMember))
+					r.With(s.requirePermission(core.PermissionMemberInvite)).Post("/invitations", s.errorHandler(s.handleCreateOrganizationInvitation))
+				})
+			})
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(s.authUser)
+				r.Use(s.authStaff)
+
+				r.Route("/users", func(r chi.Router) {
+					r.Get("/", s.errorHandler(s.handleAdminListUsers))
+					r.Get("/{userID}", s.errorHandler(s.handleAdminGetUser))
+				})
+
+				r.Route("/licenses", func(r chi.Router) {
+					r.Get("/", s.errorHandler(s.handleAdminListLicenses))
+					r.Post("/", s.errorHandler(s.handleAdminIssueLicense))
+
+					r.Route("/{licenseID}", func(r chi.Router) {
+						r.Get("/", s.errorHandler(s.handleAdminGetLicense))
+						r.Post("/suspend", s.errorHandler(s.handleAdminSuspendLicense))
+						r.Post("/reactivate", s.errorHandler(s.handleAdminReactivateLicense))
+						r.Post("/revoke", s.errorHandler(s.handleAdminRevokeLicense))
+						r.Post("/upgrade", s.errorHandler(s.handleAdminUpgradeLicense))
+						r.Post("/renew", s.errorHandler(s.handleAdminRenewLicense))
+					})
+				})
+
+				r.Route("/releases", func(r chi.Router) {
+					r.Get("/", s.errorHandler(s.handleAdminListReleases))
+					r.Post("/", s.errorHandler(s.handleAdminCreateRelease))
+					r.Get("/versions", s.errorHandler(s.handleAdminListReleaseVersions))
+
+					r.Route("/{releaseID}", func(r chi.Router) {
+						r.Put("/", s.errorHandler(s.handleAdminUpdateRelease))
+						r.Delete("/", s.errorHandler(s.handleAdminDeleteRelease))
+						r.Post("/artifacts", s.errorHandler(s.handleAdminCreateReleaseArtifact))
+						r.Delete("/artifacts/{artifactID}", s.errorHandler(s.handleAdminDeleteReleaseArtifact))
+					})
+				})
+
+				r.Route("/advisories", func(r chi.Router) {
+					r.Get("/", s.errorHandler(s.handleAdminListAdvisories))
+					r.Post("/", s.errorHandler(s.handleAdminCreateAdvisory))
+
+					r.Route("/{advisoryID}", func(r chi.Router) {
+						r.Put("/", s.errorHandler(s.handleAdminUpdateAdvisory))
+						r.Delete("/", s.errorHandler(s.handleAdminDeleteAdvisory))
+					})
+				})
+			})
+
+			r.Route("/releases", func(r chi.Router) {
+				r.Use(s.authUser)
+
+				r.Get("/", s.errorHandler(s.handleListReleases))
+				r.Get("/{releaseID}/artifacts/{artifactID}/download", s.errorHandler(s.handleDownloadReleaseArtifact))
+				r.Head("/{releaseID}/artifacts/{artifactID}/download", s.errorHandler(s.handleDownloadReleaseArtifact))
+				r.Post("/{releaseID}/artifacts/{artifactID}/download-url", s.errorHandler(s.handleCreateReleaseArtifactDownloadURL))
+			})
+
+			r.Get("/downloads/{releaseID}/{artifactID}", s.errorHandler(s.handleDownloadSignedReleaseArtifact))
+			r.Head("/downloads/{releaseID}/{artifactID}", s.errorHandler(s.handleDownloadSignedReleaseArtifact))
+
+			r.Get("/registry/token", s.errorHandler(s.handleRegistryToken))
+
+			r.With(s.authLicense).Get("/updates", s.errorHandler(s.handleCheckUpdates))
+
+			r.Route("/instances", func(r chi.Router) {
+				r.Use(s.authLicense)
+
+				r.Post("/{instanceID}/heartbeat", s.errorHandler(s.handleInstanceHeartbeat))
+			})
+
 			r.Route("/users", func(r chi.Router) {
 				r.Use(s.authUser)
 
@@ -94,6 +225,10 @@ func (s *Server) installRESTHandlers(router *chi.Mux) {
 					r.Put("/", s.errorHandler(s.handleUpdateMe))
 					r.Post("/email/instructions", s.errorHandler(s.handleSendUpdateMeEmailInstructions))
 					r.Put("/email", s.errorHandler(s.handleUpdateMeEmail))
+
+					r.Get("/audit", s.errorHandler(s.handleListMyAuditEvents))
+
+					r.Route("/license", s.installLicenseHandlers)
 				})
 			})
 		})
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/trysourcetool/onprem-portal/internal"
+	"github.com/trysourcetool/onprem-portal/internal/core"
+	"github.com/trysourcetool/onprem-portal/internal/database"
+	"github.com/trysourcetool/onprem-portal/internal/errdefs"
+	"github.com/trysourcetool/onprem-portal/internal/semver"
+)
+
+type advisoryResponse struct {
+	ID                string `json:"id"`
+	Product           string `json:"product"`
+	Identifier        string `json:"identifier"`
+	Title             string `json:"title"`
+	Description       string `json:"description"`
+	Severity          string `json:"severity"`
+	URL               string `json:"url"`
+	IntroducedVersion string `json:"introducedVersion"`
+	FixedVersion      string `json:"fixedVersion"`
+	PublishedAt       string `json:"publishedAt"`
+	CreatedAt         string `json:"createdAt"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+func advisoryFromModel(a *core.SecurityAdvisory) *advisoryResponse {
+	if a == nil {
+		return nil
+	}
+
+	return &advisoryResponse{
+		ID:                a.ID.String(),
+		Product:           a.Product,
+		Identifier:        a.Identifier,
+		Title:             a.Title,
+		Description:       a.Description,
+		Severity:          a.Severity,
+		URL:               a.URL,
+		IntroducedVersion: a.IntroducedVersion,
+		FixedVersion:      a.FixedVersion,
+		PublishedAt:       strconv.FormatInt(a.PublishedAt.Unix(), 10),
+		CreatedAt:         strconv.FormatInt(a.CreatedAt.Unix(), 10),
+		UpdatedAt:         strconv.FormatInt(a.UpdatedAt.Unix(), 10),
+	}
+}
+
+type checkUpdatesRequest struct {
+	Product string `validate:"required,max=64"`
+	Version string `validate:"required,max=64"`
+	Channel string `validate:"required,oneof=stable beta"`
+}
+
+type checkUpdatesResponse struct {
+	CurrentVersion  string `json:"currentVersion"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+	// Latest is the newest release the license may install, or null if none is published.
+	Latest     *releaseResponse    `json:"latest"`
+	Advisories []*advisoryResponse `json:"advisories"`
+}
+
+// handleCheckUpdates tells a running instance about the newest release it may upgrade
+// to and the security advisories affecting its version. Every check is recorded so we
+// can tell which versions are running in the field.
+func (s *Server) handleCheckUpdates(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	q := r.URL.Query()
+	req := checkUpdatesRequest{
+		Product: q.Get("product"),
+		Version: q.Get("version"),
+		Channel: q.Get("channel"),
+	}
+	if req.Product == "" {
+		req.Product = core.ProductSourcetool
+	}
+	if req.Channel == "" {
+		req.Channel = core.ReleaseChannelStable
+	}
+
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+
+	current, err := semver.Parse(req.Version)
+	if err != nil {
+		return errdefs.ErrInvalidArgument(err)
+	}
+
+	ctxLicense := internal.ContextLicense(ctx)
+	p, err := s.db.Plan().GetByID(ctx, ctxLicense.PlanID)
+	if err != nil {
+		return err
+	}
+
+	releases, err := s.db.Release().List(ctx,
+		database.ReleaseByProduct(req.Product),
+		database.ReleaseByEdition(p.Entitlements().Edition),
+	)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	latest, latestVersion := latestRelease(releases, req.Channel, now)
+
+	advisories, err := s.db.Advisory().List(ctx, database.AdvisoryByProduct(req.Product))
+	if err != nil {
+		return err
+	}
+
+	advisoriesRes := make([]*advisoryResponse, 0)
+	for _, a := range advisories {
+		if a.Affects(current) {
+			advisoriesRes = append(advisoriesRes, advisoryFromModel(a))
+		}
+	}
+
+	if err := s.db.Release().CreateUpdateCheck(ctx, &core.UpdateCheck{
+		ID:        uuid.Must(uuid.NewV4()),
+		LicenseID: ctxLicense.ID,
+		Product:   req.Product,
+		Version:   current.String(),
+		Channel:   req.Channel,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}); err != nil {
+		return err
+	}
+
+	res := checkUpdatesResponse{
+		CurrentVersion: current.String(),
+		Advisories:     advisoriesRes,
+	}
+	if latest != nil {
+		latestRes, err := s.releasesFromModels(r, []*core.Release{latest})
+		if err != nil {
+			return err
+		}
+		res.Latest = latestRes[0]
+		res.UpdateAvailable = latestVersion.Compare(current) > 0
+	}
+
+	return s.renderJSON(w, http.StatusOK, res)
+}
+
+// latestRelease picks the highest version among the published releases of channel.
+// The beta channel also receives stable releases, while stable never receives a
+// pre-release. Releases whose version is not semantic are skipped.
+func latestRelease(releases []*core.Release, channel string, now time.Time) (*core.Release, semver.Version) {
+	var (
+		latest        *core.Release
+		latestVersion semver.Version
+	)
+	for _, rel := range releases {
+		if rel.ReleasedAt.After(now) {
+			continue
+		}
+		if channel == core.ReleaseChannelStable && rel.Channel != core.ReleaseChannelStable {
+			continue
+		}
+
+		v, err := semver.Parse(rel.Version)
+		if err != nil {
+			continue
+		}
+		if channel == core.ReleaseChannelStable && v.IsPrerelease() {
+			continue
+		}
+
+		if latest == nil || v.Compare(latestVersion) > 0 {
+			latest, latestVersion = rel, v
+		}
+	}
+
+	return latest, latestVersion
+}
@@ -23,12 +23,13 @@ type userResponse struct {
 	Email     string           `json:"email"`
 	FirstName string           `json:"firstName"`
 	LastName  string           `json:"lastName"`
+	IsStaff   bool             `json:"isStaff"`
 	CreatedAt string           `json:"createdAt"`
 	UpdatedAt string           `json:"updatedAt"`
 	License   *licenseResponse `json:"license,omitempty"`
 }
 
-func (s *Server) userFromModel(user *core.User, l *core.License) *userResponse {
+func (s *Server) userFromModel(user *core.User, l *core.License, p *core.Plan) *userResponse {
 	if user == nil {
 		return nil
 	}
@@ -38,9 +39,10 @@ func (s *Server) userFromModel(user *core.User, l *core.License) *userResponse {
 		Email:     user.Email,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
+		IsStaff:   user.IsStaff,
 		CreatedAt: strconv.FormatInt(user.CreatedAt.Unix(), 10),
 		UpdatedAt: strconv.FormatInt(user.UpdatedAt.Unix(), 10),
-		License:   s.licenseFromModel(l),
+		License:   s.licenseFromModel(l, p),
 	}
 }
 
@@ -52,13 +54,30 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) error {
 	ctx := r.Context()
 
 	ctxUser := internal.ContextUser(ctx)
-	l, err := s.db.License().GetByUserID(ctx, ctxUser.ID)
+	l, err := s.getPrimaryLicense(ctx, ctxUser)
+	if err != nil {
+		// Members of an organization without a license, or whose license was
+		// transferred away, still have a profile.
+		if !errdefs.IsLicenseNotFound(err) {
+			return err
+		}
+		return s.renderJSON(w, http.StatusOK, getMeResponse{
+			User: s.userFromModel(ctxUser, nil, nil),
+		})
+	}
+
+	p, err := s.db.Plan().GetByID(ctx, l.PlanID)
 	if err != nil {
 		return err
 	}
 
+	// The response includes the plaintext license key.
+	if err := s.recordAudit(r, s.db.AuditEvent(), licenseAuditEntry(core.AuditActionLicenseViewed, l)); err != nil {
+		return err
+	}
+
 	return s.renderJSON(w, http.StatusOK, getMeResponse{
-		User: s.userFromModel(ctxUser, l),
+		User: s.userFromModel(ctxUser, l, p),
 	})
 }
 
@@ -103,7 +122,7 @@ func (s *Server) handleUpdateMe(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	return s.renderJSON(w, http.StatusOK, updateMeResponse{
-		User: s.userFromModel(ctxUser, nil),
+		User: s.userFromModel(ctxUser, nil, nil),
 	})
 }
 
@@ -208,6 +227,7 @@ func (s *Server) handleUpdateMeEmail(w http.ResponseWriter, r *http.Request) err
 		return errdefs.ErrUnauthenticated(errors.New("unauthorized"))
 	}
 
+	previousEmail := ctxUser.Email
 	ctxUser.Email = c.Email
 
 	if ctxUser.GoogleID != "" {
@@ -219,12 +239,19 @@ func (s *Server) handleUpdateMeEmail(w http.ResponseWriter, r *http.Request) err
 			return err
 		}
 
+		if err := s.recordAudit(r, tx.AuditEvent(), userAuditEntry(core.AuditActionUserEmailUpdated, ctxUser, map[string]any{
+			"previousEmail": previousEmail,
+			"email":         ctxUser.Email,
+		})); err != nil {
+			return err
+		}
+
 		return nil
 	}); err != nil {
 		return err
 	}
 
 	return s.renderJSON(w, http.StatusOK, updateMeEmailResponse{
-		User: s.userFromModel(ctxUser, nil),
+		User: s.userFromModel(ctxUser, nil, nil),
 	})
 }
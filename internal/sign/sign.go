@@ -0,0 +1,103 @@
+package sign
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+
+	"github.com/trysourcetool/onprem-portal/internal/config"
+)
+
+const Algorithm = "Ed25519"
+
+// Document is a detached-signature envelope. Payload holds the base64 encoded
+// JSON body and Signature is the Ed25519 signature over the decoded payload bytes,
+// so on-prem installs can verify it offline with the published public key.
+type Document struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+type Signer struct {
+	privateKey ed25519.PrivateKey
+	keyID      string
+}
+
+func NewSigner() (*Signer, error) {
+	seedB64 := config.Config.License.SigningKey
+	if seedB64 == "" {
+		return nil, errors.New("LICENSE_SIGNING_KEY not set")
+	}
+	seed, err := base64.StdEncoding.DecodeString(seedB64)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("signing key must be 32byte base64")
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	return &Signer{
+		privateKey: privateKey,
+		keyID:      KeyID(privateKey.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+// KeyID returns a short stable identifier for the public key so verifiers
+// can pick the right key when it is rotated.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+func (s *Signer) PublicKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(s.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func (s *Signer) Sign(v any) (*Document, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Algorithm: Algorithm,
+		KeyID:     s.keyID,
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.privateKey, payload)),
+	}, nil
+}
+
+func Verify(pub ed25519.PublicKey, doc *Document, v any) error {
+	if doc.Algorithm != Algorithm {
+		return errors.New("unsupported signature algorithm")
+	}
+	payload, err := base64.StdEncoding.DecodeString(doc.Payload)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(doc.Signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		return errors.New("invalid signature")
+	}
+	return json.Unmarshal(payload, v)
+}
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_license_expires_at;
+
+ALTER TABLE "license"
+  DROP COLUMN IF EXISTS "status",
+  DROP COLUMN IF EXISTS "expires_at",
+  DROP COLUMN IF EXISTS "renewed_at";
+
+END;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE "license"
+  ADD COLUMN "status"     VARCHAR(32) NOT NULL DEFAULT 'active',
+  ADD COLUMN "expires_at" TIMESTAMPTZ,
+  ADD COLUMN "renewed_at" TIMESTAMPTZ;
+
+CREATE INDEX idx_license_expires_at ON "license" ("expires_at");
+
+END;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_license_plan_id;
+ALTER TABLE "license" DROP COLUMN IF EXISTS "plan_id";
+
+DROP TRIGGER IF EXISTS update_plan_updated_at ON "plan";
+DROP TABLE IF EXISTS "plan";
+
+END;
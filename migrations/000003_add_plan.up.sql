@@ -0,0 +1,37 @@
+BEGIN;
+
+-- plan table
+CREATE TABLE "plan" (
+  "id"            UUID          NOT NULL,
+  "code"          VARCHAR(64)   NOT NULL,
+  "name"          VARCHAR(255)  NOT NULL,
+  "edition"       VARCHAR(64)   NOT NULL,
+  "seat_limit"    INTEGER       NOT NULL DEFAULT 0,
+  "max_instances" INTEGER       NOT NULL DEFAULT 0,
+  "features"      TEXT[]        NOT NULL DEFAULT '{}',
+  "support_level" VARCHAR(64)   NOT NULL,
+  "created_at"    TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at"    TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_plan_code ON "plan" ("code");
+
+CREATE TRIGGER update_plan_updated_at
+    BEFORE UPDATE ON "plan"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- A seat_limit or max_instances of 0 means unlimited.
+INSERT INTO "plan" ("id", "code", "name", "edition", "seat_limit", "max_instances", "features", "support_level") VALUES
+  (gen_random_uuid(), 'standard', 'Standard', 'standard', 0, 0, '{}', 'community'),
+  (gen_random_uuid(), 'enterprise', 'Enterprise', 'enterprise', 0, 0, '{sso,audit_log,rbac,high_availability}', 'priority');
+
+ALTER TABLE "license" ADD COLUMN "plan_id" UUID;
+UPDATE "license" SET "plan_id" = (SELECT "id" FROM "plan" WHERE "code" = 'standard');
+ALTER TABLE "license" ALTER COLUMN "plan_id" SET NOT NULL;
+ALTER TABLE "license" ADD FOREIGN KEY ("plan_id") REFERENCES "plan" ("id");
+
+CREATE INDEX idx_license_plan_id ON "license" ("plan_id");
+
+END;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- license_revocation table
+-- Rows are kept even if the license itself is deleted so that offline installs
+-- syncing the revocation list never miss an entry.
+CREATE TABLE "license_revocation" (
+  "id"         UUID          NOT NULL,
+  "seq"        BIGSERIAL     NOT NULL,
+  "license_id" UUID          NOT NULL,
+  "key_hash"   VARCHAR(255)  NOT NULL,
+  "reason"     VARCHAR(32)   NOT NULL,
+  "revoked_at" TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_license_revocation_seq ON "license_revocation" ("seq");
+CREATE INDEX idx_license_revocation_revoked_at ON "license_revocation" ("revoked_at");
+
+END;
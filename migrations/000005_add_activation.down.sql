@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_activation_updated_at ON "activation";
+DROP TABLE IF EXISTS "activation";
+
+END;
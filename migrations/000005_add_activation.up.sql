@@ -0,0 +1,27 @@
+BEGIN;
+
+-- activation table
+-- One row per on-prem instance running on a license. The row id doubles as the instance ID.
+CREATE TABLE "activation" (
+  "id"             UUID          NOT NULL,
+  "license_id"     UUID          NOT NULL,
+  "fingerprint"    VARCHAR(255)  NOT NULL,
+  "hostname"       VARCHAR(255)  NOT NULL,
+  "version"        VARCHAR(64)   NOT NULL,
+  "first_seen_at"  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "last_seen_at"   TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deactivated_at" TIMESTAMPTZ,
+  "created_at"     TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at"     TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY ("license_id") REFERENCES "license" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_activation_license_id_fingerprint ON "activation" ("license_id", "fingerprint");
+
+CREATE TRIGGER update_activation_updated_at
+    BEFORE UPDATE ON "activation"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+END;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "instance_usage_daily";
+DROP TABLE IF EXISTS "instance_heartbeat";
+
+END;
@@ -0,0 +1,34 @@
+BEGIN;
+
+-- instance_heartbeat table
+-- Raw heartbeats bucketed by hour. Rows older than the retention window are
+-- rolled up into instance_usage_daily and deleted.
+CREATE TABLE "instance_heartbeat" (
+  "id"             UUID          NOT NULL,
+  "activation_id"  UUID          NOT NULL,
+  "bucket_start"   TIMESTAMPTZ   NOT NULL,
+  "version"        VARCHAR(64)   NOT NULL,
+  "active_users"   INTEGER       NOT NULL,
+  "uptime_seconds" BIGINT        NOT NULL,
+  "received_at"    TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY ("activation_id") REFERENCES "activation" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_instance_heartbeat_activation_id_bucket_start ON "instance_heartbeat" ("activation_id", "bucket_start");
+CREATE INDEX idx_instance_heartbeat_received_at ON "instance_heartbeat" ("received_at");
+
+-- instance_usage_daily table
+CREATE TABLE "instance_usage_daily" (
+  "activation_id"      UUID              NOT NULL,
+  "day"                DATE              NOT NULL,
+  "heartbeat_count"    INTEGER           NOT NULL,
+  "max_active_users"   INTEGER           NOT NULL,
+  "avg_active_users"   DOUBLE PRECISION  NOT NULL,
+  "max_uptime_seconds" BIGINT            NOT NULL,
+  "last_version"       VARCHAR(64)       NOT NULL,
+  FOREIGN KEY ("activation_id") REFERENCES "activation" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("activation_id", "day")
+);
+
+END;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_license_user_id;
+
+ALTER TABLE "license"
+  DROP COLUMN IF EXISTS "name",
+  DROP COLUMN IF EXISTS "labels";
+
+END;
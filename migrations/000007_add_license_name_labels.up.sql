@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE "license"
+  ADD COLUMN "name"   VARCHAR(255) NOT NULL DEFAULT 'Default',
+  ADD COLUMN "labels" TEXT[]       NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_license_user_id ON "license" ("user_id");
+
+END;
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_license_organization_id;
+ALTER TABLE "license" DROP COLUMN IF EXISTS "organization_id";
+
+DROP TRIGGER IF EXISTS update_organization_member_updated_at ON "organization_member";
+DROP TABLE IF EXISTS "organization_member";
+
+DROP TRIGGER IF EXISTS update_organization_updated_at ON "organization";
+DROP TABLE IF EXISTS "organization";
+
+END;
@@ -0,0 +1,63 @@
+BEGIN;
+
+-- organization table
+CREATE TABLE "organization" (
+  "id"         UUID          NOT NULL,
+  "name"       VARCHAR(255)  NOT NULL,
+  "created_at" TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE TRIGGER update_organization_updated_at
+    BEFORE UPDATE ON "organization"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- organization_member table
+CREATE TABLE "organization_member" (
+  "id"              UUID         NOT NULL,
+  "organization_id" UUID         NOT NULL,
+  "user_id"         UUID         NOT NULL,
+  "created_at"      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at"      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY ("organization_id") REFERENCES "organization" ("id") ON DELETE CASCADE,
+  FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_organization_member_organization_id_user_id ON "organization_member" ("organization_id", "user_id");
+CREATE INDEX idx_organization_member_user_id ON "organization_member" ("user_id");
+
+CREATE TRIGGER update_organization_member_updated_at
+    BEFORE UPDATE ON "organization_member"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Move every existing user and their licenses into a personal organization.
+CREATE TEMPORARY TABLE "personal_organization" ON COMMIT DROP AS
+  SELECT
+    u."id" AS "user_id",
+    gen_random_uuid() AS "organization_id",
+    TRIM(u."first_name" || ' ' || u."last_name") AS "name"
+  FROM "user" u;
+
+INSERT INTO "organization" ("id", "name")
+  SELECT "organization_id", "name" FROM "personal_organization";
+
+INSERT INTO "organization_member" ("id", "organization_id", "user_id")
+  SELECT gen_random_uuid(), "organization_id", "user_id" FROM "personal_organization";
+
+ALTER TABLE "license" ADD COLUMN "organization_id" UUID;
+
+UPDATE "license" l
+  SET "organization_id" = p."organization_id"
+  FROM "personal_organization" p
+  WHERE l."user_id" = p."user_id";
+
+ALTER TABLE "license" ALTER COLUMN "organization_id" SET NOT NULL;
+ALTER TABLE "license" ADD FOREIGN KEY ("organization_id") REFERENCES "organization" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_license_organization_id ON "license" ("organization_id");
+
+END;
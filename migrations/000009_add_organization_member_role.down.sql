@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "organization_member" DROP COLUMN IF EXISTS "role";
+
+END;
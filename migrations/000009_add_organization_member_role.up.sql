@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Existing members created their personal organization, so they own it.
+ALTER TABLE "organization_member"
+  ADD COLUMN "role" VARCHAR(32) NOT NULL DEFAULT 'owner';
+
+ALTER TABLE "organization_member" ALTER COLUMN "role" DROP DEFAULT;
+
+END;
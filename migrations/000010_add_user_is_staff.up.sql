@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "user" ADD COLUMN "is_staff" BOOLEAN NOT NULL DEFAULT FALSE;
+
+END;
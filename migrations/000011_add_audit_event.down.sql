@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audit_event_append_only ON "audit_event";
+DROP FUNCTION IF EXISTS prevent_audit_event_modification();
+DROP TABLE IF EXISTS "audit_event";
+
+END;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- audit_event table. Rows are never updated or deleted; actors and organizations
+-- are referenced without foreign keys so that events outlive the records they describe.
+CREATE TABLE "audit_event" (
+  "id"              UUID          NOT NULL,
+  "seq"             BIGSERIAL     NOT NULL,
+  "action"          VARCHAR(64)   NOT NULL,
+  "actor_user_id"   UUID,
+  "organization_id" UUID,
+  "target_type"     VARCHAR(32)   NOT NULL DEFAULT '',
+  "target_id"       VARCHAR(255)  NOT NULL DEFAULT '',
+  "ip_address"      VARCHAR(64)   NOT NULL DEFAULT '',
+  "user_agent"      TEXT          NOT NULL DEFAULT '',
+  "request_id"      VARCHAR(255)  NOT NULL DEFAULT '',
+  "payload"         JSONB         NOT NULL DEFAULT '{}',
+  "created_at"      TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_audit_event_seq ON "audit_event" ("seq");
+CREATE INDEX idx_audit_event_actor_user_id_seq ON "audit_event" ("actor_user_id", "seq");
+CREATE INDEX idx_audit_event_organization_id_seq ON "audit_event" ("organization_id", "seq");
+
+CREATE OR REPLACE FUNCTION prevent_audit_event_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+   RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_append_only
+    BEFORE UPDATE OR DELETE ON "audit_event"
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_audit_event_modification();
+
+END;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_license_key_version;
+ALTER TABLE "license" DROP COLUMN IF EXISTS "key_version";
+
+END;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Every existing license was sealed with the single key in use so far.
+ALTER TABLE "license"
+  ADD COLUMN "key_version" INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE "license" ALTER COLUMN "key_version" DROP DEFAULT;
+
+CREATE INDEX idx_license_key_version ON "license" ("key_version");
+
+END;
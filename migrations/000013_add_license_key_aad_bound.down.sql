@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "license" DROP COLUMN IF EXISTS "key_aad_bound";
+
+END;
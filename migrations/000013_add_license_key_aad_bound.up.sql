@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Existing keys were sealed without associated data. They stay readable until
+-- `db reencrypt` re-seals them bound to their license.
+ALTER TABLE "license"
+  ADD COLUMN "key_aad_bound" BOOLEAN NOT NULL DEFAULT FALSE;
+
+END;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- encryption_key table. Holds data keys wrapped by a master key for the envelope key provider.
+CREATE TABLE "encryption_key" (
+  "version"     INTEGER      NOT NULL,
+  "wrapped_key" BYTEA        NOT NULL,
+  "created_at"  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("version")
+);
+
+END;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE "license"
+  DROP COLUMN IF EXISTS "trial_reminder_sent_at",
+  DROP COLUMN IF EXISTS "is_trial";
+
+END;
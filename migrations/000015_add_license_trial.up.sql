@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE "license"
+  ADD COLUMN "is_trial"               BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN "trial_reminder_sent_at" TIMESTAMPTZ;
+
+END;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "activation" DROP COLUMN IF EXISTS "offline";
+
+END;
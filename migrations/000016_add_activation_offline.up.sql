@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Offline activations belong to air-gapped instances that never send heartbeats.
+ALTER TABLE "activation"
+  ADD COLUMN "offline" BOOLEAN NOT NULL DEFAULT FALSE;
+
+END;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_release_artifact_updated_at ON "release_artifact";
+DROP TABLE IF EXISTS "release_artifact";
+
+DROP TRIGGER IF EXISTS update_release_updated_at ON "release";
+DROP TABLE IF EXISTS "release";
+
+END;
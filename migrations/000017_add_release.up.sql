@@ -0,0 +1,48 @@
+BEGIN;
+
+-- release table
+-- Editions lists the plan editions entitled to the release. An empty list means every edition.
+CREATE TABLE "release" (
+  "id"          UUID          NOT NULL,
+  "product"     VARCHAR(64)   NOT NULL,
+  "version"     VARCHAR(64)   NOT NULL,
+  "channel"     VARCHAR(32)   NOT NULL,
+  "notes"       TEXT          NOT NULL DEFAULT '',
+  "editions"    TEXT[]        NOT NULL DEFAULT '{}',
+  "released_at" TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "created_at"  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at"  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_release_product_version ON "release" ("product", "version");
+CREATE INDEX idx_release_released_at ON "release" ("released_at");
+
+CREATE TRIGGER update_release_updated_at
+    BEFORE UPDATE ON "release"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- release_artifact table
+-- Path is relative to ARTIFACTS_DIR.
+CREATE TABLE "release_artifact" (
+  "id"         UUID          NOT NULL,
+  "release_id" UUID          NOT NULL,
+  "name"       VARCHAR(255)  NOT NULL,
+  "path"       VARCHAR(1024) NOT NULL,
+  "size"       BIGINT        NOT NULL,
+  "sha256"     CHAR(64)      NOT NULL,
+  "created_at" TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY ("release_id") REFERENCES "release" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_release_artifact_release_id_name ON "release_artifact" ("release_id", "name");
+
+CREATE TRIGGER update_release_artifact_updated_at
+    BEFORE UPDATE ON "release_artifact"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+END;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- release_download table
+-- One row per artifact request, so resumed downloads record each range separately.
+CREATE TABLE "release_download" (
+  "id"          UUID          NOT NULL,
+  "artifact_id" UUID          NOT NULL,
+  "license_id"  UUID          NOT NULL,
+  "range"       VARCHAR(255)  NOT NULL DEFAULT '',
+  "ip_address"  VARCHAR(64)   NOT NULL DEFAULT '',
+  "user_agent"  TEXT          NOT NULL DEFAULT '',
+  "created_at"  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY ("artifact_id") REFERENCES "release_artifact" ("id") ON DELETE CASCADE,
+  FOREIGN KEY ("license_id") REFERENCES "license" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_release_download_license_id ON "release_download" ("license_id");
+CREATE INDEX idx_release_download_artifact_id ON "release_download" ("artifact_id");
+
+END;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "update_check";
+
+DROP TRIGGER IF EXISTS update_security_advisory_updated_at ON "security_advisory";
+DROP TABLE IF EXISTS "security_advisory";
+
+END;
@@ -0,0 +1,48 @@
+BEGIN;
+
+-- security_advisory table
+-- An advisory affects versions from introduced_version (inclusive) up to fixed_version
+-- (exclusive). An empty bound is open-ended.
+CREATE TABLE "security_advisory" (
+  "id"                 UUID          NOT NULL,
+  "product"            VARCHAR(64)   NOT NULL,
+  "identifier"         VARCHAR(64)   NOT NULL,
+  "title"              VARCHAR(255)  NOT NULL,
+  "description"        TEXT          NOT NULL DEFAULT '',
+  "severity"           VARCHAR(32)   NOT NULL,
+  "url"                VARCHAR(1024) NOT NULL DEFAULT '',
+  "introduced_version" VARCHAR(64)   NOT NULL DEFAULT '',
+  "fixed_version"      VARCHAR(64)   NOT NULL DEFAULT '',
+  "published_at"       TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "created_at"         TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at"         TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_security_advisory_product_identifier ON "security_advisory" ("product", "identifier");
+CREATE INDEX idx_security_advisory_published_at ON "security_advisory" ("published_at");
+
+CREATE TRIGGER update_security_advisory_updated_at
+    BEFORE UPDATE ON "security_advisory"
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- update_check table
+-- One row per update check, recording the version an instance reported.
+CREATE TABLE "update_check" (
+  "id"          UUID          NOT NULL,
+  "license_id"  UUID          NOT NULL,
+  "product"     VARCHAR(64)   NOT NULL,
+  "version"     VARCHAR(64)   NOT NULL,
+  "channel"     VARCHAR(32)   NOT NULL,
+  "ip_address"  VARCHAR(64)   NOT NULL DEFAULT '',
+  "user_agent"  TEXT          NOT NULL DEFAULT '',
+  "created_at"  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY ("license_id") REFERENCES "license" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_update_check_license_id ON "update_check" ("license_id");
+CREATE INDEX idx_update_check_product_created_at ON "update_check" ("product", "created_at");
+
+END;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE "license" DROP CONSTRAINT IF EXISTS "license_user_id_fkey";
+ALTER TABLE "license" ADD CONSTRAINT "license_user_id_fkey"
+  FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+
+END;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Licenses belong to their organization, so deleting the licensee must not delete the
+-- license. Reassign the licensee to another member before deleting the user.
+ALTER TABLE "license" DROP CONSTRAINT IF EXISTS "license_user_id_fkey";
+ALTER TABLE "license" ADD CONSTRAINT "license_user_id_fkey"
+  FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE RESTRICT;
+
+END;
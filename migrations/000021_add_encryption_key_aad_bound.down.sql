@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "encryption_key" DROP COLUMN IF EXISTS "aad_bound";
+
+END;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Existing data keys were wrapped without associated data. They stay readable until
+-- `db rewrap-data-keys` re-wraps them bound to their version.
+ALTER TABLE "encryption_key"
+  ADD COLUMN "aad_bound" BOOLEAN NOT NULL DEFAULT FALSE;
+
+END;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE "license_revocation"
+  ALTER COLUMN "revoked_at" SET DEFAULT CURRENT_TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_license_revocation_txid_seq;
+
+ALTER TABLE "license_revocation" DROP COLUMN IF EXISTS "txid";
+
+END;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- seq is allocated when a row is inserted, not when it commits, so a client paging by
+-- seq can move past a revocation whose transaction is still open. Recording the
+-- inserting transaction lets the list only hand out rows once every transaction that
+-- could precede them has finished.
+-- Existing rows all get this migration's transaction id and keep their seq order.
+ALTER TABLE "license_revocation"
+  ADD COLUMN "txid" XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE UNIQUE INDEX idx_license_revocation_txid_seq ON "license_revocation" ("txid", "seq");
+
+-- CURRENT_TIMESTAMP is the start of the transaction, which can be well before the row
+-- is inserted.
+ALTER TABLE "license_revocation"
+  ALTER COLUMN "revoked_at" SET DEFAULT clock_timestamp();
+
+END;